@@ -0,0 +1,219 @@
+/*
+Package ambiguity 文法二义性检查包
+
+文法是否二义是不可判定的 这里做有界的检查 枚举长度不超过上界的全部句子
+用 Earley 分析器得到每个句子的语法森林 森林中有多种推导方式的句子就是二义的证据
+另外从产生式的形状识别常见的二义模式 悬空 else 二义的二元运算符 可空的环形推导
+*/
+package ambiguity
+
+import (
+	"errors"
+	"fmt"
+	"github.com/esonhugh/compiler/grammarEarley"
+	"github.com/esonhugh/compiler/grammarLL1/rule"
+	"github.com/esonhugh/compiler/grammarLL1/util"
+	"github.com/esonhugh/compiler/tree"
+	"sort"
+	"strings"
+)
+
+// DefaultBound 没有指定时枚举的句子最大长度
+const DefaultBound = 6
+
+// Options 检查的范围
+type Options struct {
+	Bound        int // Bound 句子的最大长度 小于等于 0 时为 DefaultBound
+	MaxSentences int // MaxSentences 最多分析的句子个数 小于等于 0 时不限制
+	MaxWitnesses int // MaxWitnesses 找到这么多二义的句子后停止 小于等于 0 时不限制
+}
+
+// Witness 二义的句子 以及它的两棵不同的语法树
+// 森林中的环形推导只展开一次 只经过环形推导产生二义时 Trees 中可能只有一棵树
+type Witness struct {
+	Sentence []string
+	Trees    []*tree.Node
+	Cyclic   bool // Cyclic 句子有无穷多棵语法树
+	names    map[string]string
+}
+
+// Report 检查结果
+type Report struct {
+	Start     string
+	Bound     int
+	Sentences int        // Sentences 分析过的句子个数
+	Truncated bool       // Truncated 是否因为 MaxSentences 没有检查完全部句子
+	Witnesses []*Witness // Witnesses 按长度从短到长排列
+	Patterns  []*Pattern // Patterns 识别出的二义模式
+}
+
+// IsAmbiguous 找到了二义的句子
+// 没有找到时文法在长度上界内无二义 但是更长的句子仍然可能二义
+func (r *Report) IsAmbiguous() bool {
+	return len(r.Witnesses) != 0
+}
+
+// String 输出检查结果 每个二义的句子输出两棵语法树
+func (r *Report) String() string {
+	var build strings.Builder
+	for _, p := range r.Patterns {
+		build.WriteString(p.String())
+	}
+	for _, w := range r.Witnesses {
+		build.WriteString(w.String())
+	}
+	limit := ""
+	if r.Truncated {
+		limit = " (truncated)"
+	}
+	if !r.IsAmbiguous() {
+		build.WriteString(fmt.Sprintf("no ambiguous sentence up to length %d, %d sentences checked%s\n", r.Bound, r.Sentences, limit))
+	} else {
+		build.WriteString(fmt.Sprintf("ambiguous sentences: %d up to length %d, %d sentences checked%s\n", len(r.Witnesses), r.Bound, r.Sentences, limit))
+	}
+	return build.String()
+}
+
+// String 输出句子和它的语法树
+func (w *Witness) String() string {
+	var build strings.Builder
+	build.WriteString(fmt.Sprintf("ambiguous sentence: %s\n", sentence(w.Sentence)))
+	for i, t := range w.Trees {
+		build.WriteString(fmt.Sprintf("  tree %d: %s\n", i+1, t.Format(w.names)))
+	}
+	if w.Cyclic {
+		build.WriteString("  infinitely many trees through a cyclic derivation\n")
+	}
+	return build.String()
+}
+
+func sentence(s []string) string {
+	if len(s) == 0 {
+		return "ε"
+	}
+	return strings.Join(s, " ")
+}
+
+// Check 检查文法 start 为开始符号
+// 句子按长度从短到长 同样长度的按符号编号的顺序分析 因此结果是确定的
+// 句子按长度逐层枚举 达到 MaxSentences 或 MaxWitnesses 后不再求更长的句子
+func Check(r *rule.Rule, start string, opts Options) (*Report, error) {
+	if _, ok := r.Rules[start]; !ok {
+		return nil, errors.New("start symbol " + r.Name(start) + " has no production")
+	}
+	if opts.Bound <= 0 {
+		opts.Bound = DefaultBound
+	}
+	report := &Report{Start: start, Bound: opts.Bound, Patterns: Patterns(r)}
+	lang := newLanguage(r)
+	symbols := r.Symbols()
+	parser := grammarEarley.NewParser(r, start)
+	for n := 0; n <= opts.Bound; n++ {
+		var sentences []string
+		for s := range lang.level(n)[start] {
+			sentences = append(sentences, s)
+		}
+		sort.Slice(sentences, func(i, j int) bool {
+			return symbols.LessString(sentences[i], sentences[j])
+		})
+		for _, s := range sentences {
+			if opts.MaxSentences > 0 && report.Sentences >= opts.MaxSentences {
+				report.Truncated = true
+				return report, nil
+			}
+			report.Sentences++
+			terminals := strings.Split(s, "")
+			forest, err := parser.ParseTerminals(terminals)
+			if err != nil {
+				return nil, fmt.Errorf("sentence %s derived but rejected: %v", sentence(terminals), err)
+			}
+			if !forest.IsAmbiguous() {
+				continue
+			}
+			trees := forest.Trees(2)
+			report.Witnesses = append(report.Witnesses, &Witness{Sentence: terminals, Trees: trees, Cyclic: len(trees) < 2, names: r.Names})
+			if opts.MaxWitnesses > 0 && len(report.Witnesses) >= opts.MaxWitnesses {
+				return report, nil
+			}
+		}
+	}
+	return report, nil
+}
+
+// language 每个非终结符推导出的终结符串 按长度逐层求出
+// lang[n][A] 为 A 推导出的长度为 n 的全部终结符串 只用到更短的层和这一层本身 对每一层做不动点迭代
+type language struct {
+	nonterminals []string
+	formulas     []*rule.Formula
+	lang         []map[string]map[string]bool
+}
+
+func newLanguage(r *rule.Rule) *language {
+	return &language{nonterminals: r.Nonterminals(), formulas: r.Formulas()}
+}
+
+// level 长度为 n 的一层 还没有求出时依次求出到第 n 层
+func (l *language) level(n int) map[string]map[string]bool {
+	for len(l.lang) <= n {
+		m := len(l.lang)
+		current := make(map[string]map[string]bool)
+		for _, a := range l.nonterminals {
+			current[a] = make(map[string]bool)
+		}
+		l.lang = append(l.lang, current)
+		for changed := true; changed; {
+			changed = false
+			for _, f := range l.formulas {
+				for _, s := range l.yields(strings.ReplaceAll(f.Right, "&", ""), m) {
+					if !current[f.Left][s] {
+						current[f.Left][s] = true
+						changed = true
+					}
+				}
+			}
+		}
+	}
+	return l.lang[n]
+}
+
+// yields 符号串 alpha 能推导出的长度为 n 的终结符串 只用到已经求出的层
+func (l *language) yields(alpha string, n int) []string {
+	if alpha == "" {
+		if n == 0 {
+			return []string{""}
+		}
+		return nil
+	}
+	// 每个终结符至少占一个位置
+	terminals := 0
+	for i := 0; i < len(alpha); i++ {
+		if util.IsTerminal(alpha[i]) {
+			terminals++
+		}
+	}
+	if terminals > n {
+		return nil
+	}
+	var res []string
+	x := alpha[0:1]
+	if util.IsTerminal(alpha[0]) {
+		for _, rest := range l.yields(alpha[1:], n-1) {
+			res = append(res, x+rest)
+		}
+		return res
+	}
+	for m := 0; m <= n; m++ {
+		// 未定义的非终结符推导不出任何串
+		heads := l.lang[m][x]
+		if len(heads) == 0 {
+			continue
+		}
+		tails := l.yields(alpha[1:], n-m)
+		for head := range heads {
+			for _, tail := range tails {
+				res = append(res, head+tail)
+			}
+		}
+	}
+	return res
+}
@@ -0,0 +1,101 @@
+package ambiguity
+
+import (
+	"github.com/esonhugh/compiler/grammarLL1/rule"
+	"strings"
+	"testing"
+)
+
+func check(t *testing.T, rules, start string, opts Options) *Report {
+	report, err := Check(rule.MustParse(rules), start, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(report.String())
+	return report
+}
+
+func kinds(report *Report) string {
+	var res []string
+	for _, p := range report.Patterns {
+		res = append(res, p.Kind)
+	}
+	return strings.Join(res, ", ")
+}
+
+func TestBinaryOperator(t *testing.T) {
+	report := check(t, "E->E+E|E*E|i", "E", Options{Bound: 5, MaxWitnesses: 1})
+	if !report.IsAmbiguous() {
+		t.Fatal("E->E+E is ambiguous")
+	}
+	w := report.Witnesses[0]
+	if got := strings.Join(w.Sentence, ""); got != "i*i*i" {
+		t.Fatalf("shortest witness = %s", got)
+	}
+	if len(w.Trees) != 2 || w.Trees[0].Equal(w.Trees[1]) {
+		t.Fatal("witness should have two distinct trees")
+	}
+	if got := kinds(report); got != BinaryOperator+", "+BinaryOperator {
+		t.Fatalf("patterns = %s", got)
+	}
+}
+
+func TestDanglingElse(t *testing.T) {
+	for _, rules := range []string{
+		"S->iEtS|iEtSeS|a\nE->b",
+		"S->iEtSP|a\nP->eS|&\nE->b",
+	} {
+		report := check(t, rules, "S", Options{Bound: 9})
+		if got := kinds(report); got != DanglingElse {
+			t.Errorf("%q: patterns = %s", rules, got)
+		}
+		if len(report.Witnesses) != 1 || strings.Join(report.Witnesses[0].Sentence, "") != "ibtibtaea" {
+			t.Errorf("%q: witnesses %v", rules, report.Witnesses)
+		}
+		// S 和 E 没有声明 %name 按原样显示
+		if got := report.String(); !strings.Contains(got, "dangling else at S: S->iEtS") || !strings.Contains(got, "tree 1: S(i E(b) t S(") {
+			t.Errorf("%q: report\n%s", rules, got)
+		}
+	}
+	report := check(t, "%name S Stmt\nS->iEtS|iEtSeS|a\nE->b", "S", Options{Bound: 9})
+	if got := report.String(); !strings.Contains(got, "dangling else at Stmt: Stmt->iEtStmt, Stmt->iEtStmteStmt") {
+		t.Errorf("report\n%s", got)
+	}
+}
+
+func TestNullableCycle(t *testing.T) {
+	report := check(t, "A->B|a\nB->A|b", "A", Options{Bound: 2})
+	if got := kinds(report); got != NullableCycle {
+		t.Fatalf("patterns = %s", got)
+	}
+	if !strings.Contains(report.Patterns[0].Message, "A => B => A") {
+		t.Fatalf("message = %s", report.Patterns[0].Message)
+	}
+	if !report.IsAmbiguous() || strings.Join(report.Witnesses[0].Sentence, "") != "a" {
+		t.Fatal("a has infinitely many parse trees")
+	}
+	if !report.Witnesses[0].Cyclic {
+		t.Fatal("witness should be marked cyclic")
+	}
+	// 经过可空符号的环
+	if got := kinds(check(t, "A->AB|a\nB->&|b", "A", Options{Bound: 2})); got != NullableCycle {
+		t.Fatalf("patterns = %s", got)
+	}
+}
+
+func TestUnambiguous(t *testing.T) {
+	report := check(t, "E->E+T|T\nT->T*F|F\nF->(E)|i", "E", Options{Bound: 7})
+	if report.IsAmbiguous() || len(report.Patterns) != 0 {
+		t.Fatal("expression grammar is unambiguous")
+	}
+	if report.Sentences == 0 || report.Truncated {
+		t.Fatalf("%d sentences checked", report.Sentences)
+	}
+	// 句子按长度逐层枚举 上界很大时也只求出前几层
+	if r := check(t, "E->E+T|T\nT->T*F|F\nF->(E)|i", "E", Options{Bound: 100, MaxSentences: 3}); !r.Truncated || r.Sentences != 3 {
+		t.Fatal("MaxSentences should be respected")
+	}
+	if _, err := Check(rule.MustParse("E->i"), "X", Options{}); err == nil {
+		t.Fatal("undefined start symbol")
+	}
+}
@@ -0,0 +1,203 @@
+package ambiguity
+
+import (
+	"fmt"
+	"github.com/esonhugh/compiler/grammarLL1/first"
+	"github.com/esonhugh/compiler/grammarLL1/rule"
+	"github.com/esonhugh/compiler/grammarLL1/util"
+	"github.com/esonhugh/compiler/tree"
+	"github.com/esonhugh/compiler/util/transfer"
+	"strings"
+)
+
+// 二义模式的种类
+const (
+	DanglingElse   = "dangling else"
+	BinaryOperator = "ambiguous binary operator"
+	NullableCycle  = "nullable cycle"
+)
+
+// Pattern 从产生式的形状识别出的二义模式
+type Pattern struct {
+	Kind        string
+	Nonterminal string          // Nonterminal 出现问题的非终结符
+	Formulas    []*rule.Formula // Formulas 构成这个模式的产生式
+	Message     string
+	names       map[string]string
+}
+
+// String 输出模式和相关的产生式
+func (p *Pattern) String() string {
+	var formulas []string
+	for _, f := range p.Formulas {
+		formulas = append(formulas, transfer.TransferWith(f.Left+"->"+f.Right, p.names))
+	}
+	return fmt.Sprintf("%s at %s: %s\n  %s\n", p.Kind, tree.Name(p.Nonterminal, p.names), strings.Join(formulas, ", "), p.Message)
+}
+
+// Patterns 识别文法中的全部二义模式 按非终结符的声明顺序 符号按文法的 %name 显示
+func Patterns(r *rule.Rule) []*Pattern {
+	nullable := first.GetNullableSet(r)
+	var res []*Pattern
+	res = append(res, binaryOperators(r)...)
+	res = append(res, danglingElse(r, nullable)...)
+	res = append(res, nullableCycles(r, nullable)...)
+	for _, p := range res {
+		p.names = r.Names
+	}
+	return res
+}
+
+// binaryOperators A->AαA 且 α 中有终结符 A 既左递归又右递归 a α a α a 有两种结合方式
+func binaryOperators(r *rule.Rule) []*Pattern {
+	var res []*Pattern
+	for _, f := range r.Formulas() {
+		right := f.Right
+		if len(right) < 3 || right[0:1] != f.Left || right[len(right)-1:] != f.Left {
+			continue
+		}
+		operator := ""
+		for i := 1; i < len(right)-1; i++ {
+			if util.IsTerminal(right[i]) && right[i] != '&' {
+				operator = right[i : i+1]
+				break
+			}
+		}
+		if operator == "" {
+			continue
+		}
+		message := fmt.Sprintf("%s is both left and right recursive, associativity of %s is unspecified", r.Name(f.Left), operator)
+		if _, ok := r.Precedences[operator]; ok {
+			message += " (resolved by %left/%right for LR parsers, the grammar itself is still ambiguous)"
+		}
+		res = append(res, &Pattern{Kind: BinaryOperator, Nonterminal: f.Left, Formulas: []*rule.Formula{f}, Message: message})
+	}
+	return res
+}
+
+// danglingElse 两种写法
+// A->αA 和 A->αAβA 同时存在 例如 C->iEtC|iEtCeC
+// 或者提取公因子后 A->αAB B 可空且 B->βA 例如 C->iEtCP P->eC|&
+// α A β A 中的 β 可以属于内层的 A 也可以属于外层的 A
+func danglingElse(r *rule.Rule, nullable first.Nullable) []*Pattern {
+	var res []*Pattern
+	for _, left := range r.Nonterminals() {
+		rights := r.Rules[left]
+		for _, short := range rights {
+			if len(short) < 2 || short[len(short)-1:] != left {
+				continue
+			}
+			for _, long := range rights {
+				if len(long) > len(short)+1 && strings.HasPrefix(long, short) && long[len(long)-1:] == left {
+					res = append(res, &Pattern{
+						Kind: DanglingElse, Nonterminal: left,
+						Formulas: []*rule.Formula{{Left: left, Right: short}, {Left: left, Right: long}},
+						Message:  fmt.Sprintf("%s can attach to either of the nested %s", r.Name(long[len(short):len(long)-1]), r.Name(left)),
+					})
+				}
+			}
+		}
+		for _, right := range rights {
+			if len(right) < 3 || right[len(right)-2:len(right)-1] != left {
+				continue
+			}
+			tail := right[len(right)-1:]
+			if util.IsTerminal(tail[0]) || !nullable[tail] {
+				continue
+			}
+			for _, other := range r.Rules[tail] {
+				if len(other) >= 2 && other[len(other)-1:] == left && !nullable.IsNullable(other[:len(other)-1]) {
+					res = append(res, &Pattern{
+						Kind: DanglingElse, Nonterminal: left,
+						Formulas: []*rule.Formula{{Left: left, Right: right}, {Left: tail, Right: other}},
+						Message:  fmt.Sprintf("%s can attach to either of the nested %s", r.Name(other[:len(other)-1]), r.Name(left)),
+					})
+				}
+			}
+		}
+	}
+	return res
+}
+
+// cycleEdge A->αBβ 且 α β 都可空 A 可以只推导出 B
+type cycleEdge struct {
+	to      string
+	formula *rule.Formula
+}
+
+// nullableCycles A=>+A 时含有 A 的句子有无穷多棵语法树
+// 除去可空的符号后只剩一个非终结符的产生式构成一条边 找出图中的环
+func nullableCycles(r *rule.Rule, nullable first.Nullable) []*Pattern {
+	edges := make(map[string][]cycleEdge)
+	for _, f := range r.Formulas() {
+		for i := 0; i < len(f.Right); i++ {
+			x := f.Right[i : i+1]
+			if util.IsTerminal(f.Right[i]) {
+				continue
+			}
+			if nullable.IsNullable(f.Right[:i]) && nullable.IsNullable(f.Right[i+1:]) {
+				edges[f.Left] = append(edges[f.Left], cycleEdge{to: x, formula: f})
+			}
+		}
+	}
+	var res []*Pattern
+	reported := make(map[string]bool)
+	for _, start := range r.Nonterminals() {
+		path := findCycle(edges, start)
+		if path == nil {
+			continue
+		}
+		// 同一个环只报告一次
+		members := make(map[string]bool)
+		var names []string
+		for _, e := range path {
+			members[e.formula.Left] = true
+		}
+		for _, n := range r.Nonterminals() {
+			if members[n] {
+				names = append(names, n)
+			}
+		}
+		key := strings.Join(names, "")
+		if reported[key] {
+			continue
+		}
+		reported[key] = true
+		chain := []string{r.Name(start)}
+		var formulas []*rule.Formula
+		for _, e := range path {
+			chain = append(chain, r.Name(e.to))
+			formulas = append(formulas, e.formula)
+		}
+		res = append(res, &Pattern{
+			Kind: NullableCycle, Nonterminal: start, Formulas: formulas,
+			Message: fmt.Sprintf("%s, every sentence derived through %s has infinitely many parse trees", strings.Join(chain, " => "), r.Name(start)),
+		})
+	}
+	return res
+}
+
+// findCycle 从 start 出发回到 start 的最短路径 广度优先 边按产生式顺序
+func findCycle(edges map[string][]cycleEdge, start string) []cycleEdge {
+	type visit struct {
+		at   string
+		path []cycleEdge
+	}
+	queue := []visit{{at: start}}
+	seen := make(map[string]bool)
+	for len(queue) != 0 {
+		v := queue[0]
+		queue = queue[1:]
+		for _, e := range edges[v.at] {
+			path := append(append([]cycleEdge(nil), v.path...), e)
+			if e.to == start {
+				return path
+			}
+			if !seen[e.to] {
+				seen[e.to] = true
+				queue = append(queue, visit{at: e.to, path: path})
+			}
+		}
+	}
+	return nil
+}
@@ -1,9 +1,17 @@
 package main
 
 import (
+	"context"
+	"fmt"
+	"github.com/esonhugh/compiler/ambiguity"
+	"github.com/esonhugh/compiler/dot"
+	"github.com/esonhugh/compiler/fuzz"
 	"github.com/esonhugh/compiler/grammar"
 	"github.com/esonhugh/compiler/grammarLL1"
+	"github.com/esonhugh/compiler/grammarLL1/rule"
+	"github.com/esonhugh/compiler/grammarLR"
 	"github.com/esonhugh/compiler/lexer"
+	"github.com/esonhugh/compiler/parser"
 	servicePrint "github.com/esonhugh/compiler/print"
 	"github.com/gookit/color"
 	"os"
@@ -25,20 +33,59 @@ func MakeToken(code string) []*lexer.Token {
 
 // Grammar 语法分析 同时输出结果 递归下降 也就是自顶向下解析
 func Grammar(tokens []*lexer.Token) {
-	gram, correct := grammar.Analyse(tokens)
-	if !correct {
-		panic("语法推导失败")
+	// 结果是正确语句的最左推导 有错误时输出全部错误
+	gram, diagnostics := grammar.Analyse(tokens)
+	if len(diagnostics) != 0 {
+		color.Redln(diagnostics.String())
+		return
 	}
 	servicePrint.PrintGrammar(gram)
 }
 
 // GrammarLL1 语法分析 同时输出结果 LL1 解析
 func GrammarLL1(tokens []*lexer.Token) {
-	gram, correct := grammarLL1.Analyze(tokens, "E->TG\nG->ATG|&\nT->FS\nS->MFS|&\nF->(E)|i\nA->+|-\nM->*|/", "E")
+	res, err := grammarLL1.Analyze(tokens, grammar.Rules, "E")
+	if res.Report != nil && !res.Report.IsClean() {
+		color.Yellowln(res.Report.String())
+	}
+	if err != nil {
+		color.Redln(err.Error())
+		panic("语法推导失败")
+	}
+	fmt.Println(res.First.String())
+	fmt.Println(res.Follow.String())
+	fmt.Println(res.Table.String())
+	fmt.Println(res.Trace.String())
+	if !res.Accepted {
+		panic("语法推导失败")
+	}
+	servicePrint.PrintGrammarLL1(res.Productions, rule.MustParse(grammar.Rules).Names)
+}
+
+// GrammarLR 语法分析 同时输出结果 SLR(1) 自底向上解析
+func GrammarLR(tokens []*lexer.Token) {
+	gram, correct := grammarLR.Analyze(tokens, "E->E+T|E-T|T\nT->T*F|T/F|F\nF->(E)|i", "E")
 	if !correct {
 		panic("语法推导失败")
 	}
-	servicePrint.PrintGrammarLL1(gram)
+	servicePrint.PrintGrammarLR(gram, nil)
+}
+
+// ParseWith 按名字选择分析器后端 输出语法树或者全部错误
+func ParseWith(backend string, rules string, start string, tokens []*lexer.Token) {
+	p, err := parser.New(backend, rules, start)
+	if err != nil {
+		color.Redln(err.Error())
+		return
+	}
+	root, diagnostics := p.Parse(context.Background(), parser.FromTokens(tokens))
+	for _, d := range diagnostics {
+		color.Redln(d.String())
+	}
+	if root != nil {
+		r, _ := rule.Parse(rules)
+		fmt.Printf("%s: %s\n", backend, root.Format(r.Names))
+	}
 }
 
 // 实验
@@ -51,6 +98,74 @@ func main_proxy() {
 	main_proxy_sysy()
 	main_proxy_grammar()
 	main_proxy_LL1()
+	main_proxy_LR()
+	main_proxy_backends()
+	main_proxy_dot()
+	main_proxy_ambiguity()
+	main_proxy_fuzz()
+}
+
+// main_proxy_fuzz 随机生成表达式和变异后的错误表达式 用 LR(1) 后端分析
+func main_proxy_fuzz() {
+	rules := "E->E+T|E-T|T\nT->T*F|T/F|F\nF->(E)|i"
+	r := rule.NewRules()
+	_ = r.AddRules(rules)
+	g, err := fuzz.New(r, "E", fuzz.Options{MaxDepth: 6, Coverage: true})
+	if err != nil {
+		color.Redln(err.Error())
+		return
+	}
+	for i := 0; i < 3; i++ {
+		ParseWith(parser.LR1, rules, "E", MakeToken(fuzz.Code(r, g.Sentence())))
+	}
+	if m, ok := g.Invalid(20); ok {
+		ParseWith(parser.LR1, rules, "E", MakeToken(fuzz.Code(r, m.Sentence)))
+	}
+}
+
+// main_proxy_ambiguity 检查二义文法 输出二义的句子和它的两棵语法树
+func main_proxy_ambiguity() {
+	r := rule.NewRules()
+	_ = r.AddRules("E->E+E|E*E|(E)|i")
+	report, err := ambiguity.Check(r, "E", ambiguity.Options{Bound: 5, MaxWitnesses: 2})
+	if err != nil {
+		color.Redln(err.Error())
+		return
+	}
+	fmt.Print(report.String())
+}
+
+// main_proxy_dot 输出语法树和文法依赖图的 DOT 源代码 可以用 dot -Tpng 画图
+func main_proxy_dot() {
+	p, err := parser.New(parser.LL1, grammar.Rules, "E")
+	if err != nil {
+		color.Redln(err.Error())
+		return
+	}
+	root, _ := p.Parse(context.Background(), parser.FromTokens(MakeToken("i*(i-i)")))
+	r := rule.MustParse(grammar.Rules)
+	if root != nil {
+		fmt.Print(dot.Tree(root, r.Names))
+	}
+	fmt.Print(dot.Grammar(r, "E"))
+}
+
+// main_proxy_backends 用全部后端分析同一个表达式
+func main_proxy_backends() {
+	tokens := MakeToken("i*(i-i)/(i+i)")
+	for _, backend := range parser.Backends() {
+		rules := "E->E+T|E-T|T\nT->T*F|T/F|F\nF->(E)|i"
+		if backend == parser.RecursiveDescent || backend == parser.LL1 {
+			rules = grammar.Rules
+		}
+		ParseWith(backend, rules, "E", tokens)
+	}
+}
+
+// main_proxy_LR SLR(1) 分析实验
+func main_proxy_LR() {
+	tokens := MakeToken("i*(i-i)/(i+i)")
+	GrammarLR(tokens)
 }
 
 // main_proxy_LL1 LL11 分析实验 4 and 6
@@ -0,0 +1,85 @@
+/*
+parsergen 由 LL(1) 文法生成独立的 Go 分析器 可以在 go:generate 中使用
+
+	//go:generate go run github.com/esonhugh/compiler/cmd/parsergen -grammar expr.grammar -start E -package expr -o parser.go
+
+文法文件的格式与 rule.Rule.AddRules 相同 -json 时输出导出的分析表而不是 Go 代码
+-style 选择生成的分析器 table 为表驱动 descent 为递归下降 pseudo 输出递归下降的伪代码
+文法检查发现的问题输出到标准错误 -strip 时先删除无用符号再生成 否则文法保持不变
+*/
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/esonhugh/compiler/codegen"
+	"github.com/esonhugh/compiler/grammarLL1"
+	"os"
+	"path/filepath"
+)
+
+func main() {
+	grammarFile := flag.String("grammar", "", "grammar file")
+	start := flag.String("start", "E", "start symbol")
+	pkg := flag.String("package", "", "package name of the generated parser, defaults to $GOPACKAGE")
+	output := flag.String("o", "", "output file, defaults to stdout")
+	asJSON := flag.Bool("json", false, "write the exported parse tables as JSON instead of Go code")
+	style := flag.String("style", "table", "parser style: table, descent or pseudo")
+	strip := flag.Bool("strip", false, "remove unreachable and unproductive symbols before generating")
+	flag.Parse()
+
+	if err := run(*grammarFile, *start, *pkg, *output, *style, *asJSON, *strip); err != nil {
+		fmt.Fprintln(os.Stderr, "parsergen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(grammarFile, start, pkg, output, style string, asJSON, strip bool) error {
+	if grammarFile == "" {
+		return fmt.Errorf("-grammar is required")
+	}
+	raw, err := os.ReadFile(grammarFile)
+	if err != nil {
+		return err
+	}
+	rules, report, err := grammarLL1.StripUseless(string(raw), start)
+	if err != nil {
+		return err
+	}
+	if !report.IsClean() {
+		fmt.Fprint(os.Stderr, report.String())
+	}
+	if !strip {
+		rules = string(raw)
+	}
+	tables, err := codegen.Export(rules, start)
+	if err != nil {
+		return err
+	}
+	if pkg == "" {
+		pkg = os.Getenv("GOPACKAGE")
+	}
+	opts := codegen.Options{Package: pkg, Source: filepath.Base(grammarFile)}
+	var src []byte
+	switch {
+	case asJSON:
+		src, err = json.MarshalIndent(tables, "", "  ")
+	case style == "table":
+		src, err = codegen.GenerateGo(tables, opts)
+	case style == "descent":
+		src, err = codegen.GenerateDescentGo(tables, opts)
+	case style == "pseudo":
+		src = []byte(codegen.GeneratePseudo(tables))
+	default:
+		err = fmt.Errorf("unknown style %q", style)
+	}
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		_, err = os.Stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(output, src, 0644)
+}
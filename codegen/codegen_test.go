@@ -0,0 +1,81 @@
+package codegen
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+)
+
+// TestExampleUpToDate 提交的示例分析器必须与生成器的输出一致
+func TestExampleUpToDate(t *testing.T) {
+	grammar, err := os.ReadFile("example/expr/expr.grammar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tables, err := Export(string(grammar), "E")
+	if err != nil {
+		t.Fatal(err)
+	}
+	examples := []struct {
+		file     string
+		generate func(*Tables, Options) ([]byte, error)
+		opts     Options
+	}{
+		{"example/expr/parser.go", GenerateGo, Options{Package: "expr", Source: "expr.grammar"}},
+		{"example/exprrd/parser.go", GenerateDescentGo, Options{Package: "exprrd", Source: "expr.grammar"}},
+	}
+	for _, e := range examples {
+		src, err := e.generate(tables, e.opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		committed, err := os.ReadFile(e.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(src, committed) {
+			t.Errorf("%s is out of date, run go generate ./codegen/...", e.file)
+		}
+	}
+}
+
+func TestExport(t *testing.T) {
+	tables, err := Export("%name G E'\nE->TG\nG->+TG|&\nT->(E)|i", "E")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tables.Terminals) != 5 || tables.Terminals[0].Ident != "TokEOF" || tables.Terminals[4].Ident != "TokI" {
+		t.Fatalf("unexpected terminals %v", tables.Terminals)
+	}
+	if tables.Start != len(tables.Terminals) || tables.Symbol(tables.Start).Name != "E" {
+		t.Fatalf("unexpected start %d", tables.Start)
+	}
+	// G 遇到 # 时使用 G->ε
+	if p := tables.Table[1][0]; p < 0 || tables.Productions[p].Text != "E'->ε" || tables.Productions[p].Right != nil {
+		t.Fatalf("unexpected table %v", tables.Table)
+	}
+	if _, err = Export("E->E+i|i", "E"); err == nil {
+		t.Fatal("left recursive grammar is not LL(1)")
+	}
+	if _, err = GenerateGo(tables, Options{}); err == nil {
+		t.Fatal("package name is required")
+	}
+}
+
+func TestPseudo(t *testing.T) {
+	tables, err := Export("%name G E'\nE->TG\nG->+TG|&\nT->(E)|i", "E")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pseudo := GeneratePseudo(tables)
+	for _, want := range []string{
+		"procedure E'()\n    switch lookahead\n    case '+':    -- E'->+TE'\n        match('+')\n        T()\n        E'()\n",
+		"    case '#', ')':    -- E'->ε\n        skip\n",
+		"        error(expected '(' or 'i')\n",
+	} {
+		if !strings.Contains(pseudo, want) {
+			t.Fatalf("missing %q in\n%s", want, pseudo)
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// procedure 一个非终结符的分析过程
+type procedure struct {
+	Symbol       Symbol
+	Func         string        // Func 生成代码中的函数名
+	Alternatives []alternative // Alternatives 可以选择的产生式 按产生式顺序
+	Expected     []Symbol      // Expected 可以接受的全部终结符 用于报错
+}
+
+// alternative 过程中的一个分支 遇到 Lookahead 中的终结符时使用产生式 Production
+type alternative struct {
+	Production int
+	Text       string
+	Lookahead  []Symbol
+	Right      []step
+}
+
+// step 产生式右部的一个符号 终结符匹配 非终结符调用对应的过程
+type step struct {
+	Symbol   Symbol
+	Terminal bool
+	Func     string
+}
+
+// procedures 由分析表得到每个非终结符的分析过程
+// 分支的向前看集合就是分析表中这一产生式所在的列 即 FIRST 集以及可空时的 FOLLOW 集
+func procedures(t *Tables) []procedure {
+	var res []procedure
+	funcs := funcNames(t)
+	for n, nt := range t.Nonterminals {
+		proc := procedure{Symbol: nt, Func: funcs[nt.Name]}
+		for p, production := range t.Productions {
+			if production.Left != n+len(t.Terminals) {
+				continue
+			}
+			alt := alternative{Production: p, Text: production.Text}
+			for kind, q := range t.Table[n] {
+				if q == p {
+					alt.Lookahead = append(alt.Lookahead, t.Terminals[kind])
+				}
+			}
+			if len(alt.Lookahead) == 0 {
+				continue
+			}
+			for _, symbol := range production.Right {
+				s := step{Symbol: t.Symbol(symbol), Terminal: t.IsTerminal(symbol)}
+				if !s.Terminal {
+					s.Func = funcs[s.Symbol.Name]
+				}
+				alt.Right = append(alt.Right, s)
+			}
+			proc.Alternatives = append(proc.Alternatives, alt)
+		}
+		for kind, q := range t.Table[n] {
+			if q >= 0 {
+				proc.Expected = append(proc.Expected, t.Terminals[kind])
+			}
+		}
+		res = append(res, proc)
+	}
+	return res
+}
+
+// funcNames 每个非终结符的分析过程名 由显示名得到 E' 为 parseEPrime
+// 显示名中不能用在标识符里的字符被去掉 得到的名字为空或者重复时改用 Ident
+func funcNames(t *Tables) map[string]string {
+	res := make(map[string]string)
+	used := make(map[string]bool)
+	for _, nt := range t.Nonterminals {
+		var build strings.Builder
+		for _, c := range strings.ReplaceAll(nt.Display, "'", "Prime") {
+			if c == '_' || unicode.IsLetter(c) || unicode.IsDigit(c) {
+				build.WriteRune(c)
+			}
+		}
+		name := "parse" + build.String()
+		if build.Len() == 0 || used[name] {
+			name = "parse" + nt.Ident
+		}
+		used[name] = true
+		res[nt.Name] = name
+	}
+	return res
+}
+
+// GenerateDescentGo 生成一个独立的递归下降 Go 分析器 每个非终结符对应一个函数
+// 导出的类型和函数与 GenerateGo 相同 两种分析器可以互相替换
+func GenerateDescentGo(t *Tables, opts Options) ([]byte, error) {
+	if opts.Package == "" {
+		return nil, fmt.Errorf("package name is required")
+	}
+	var buf bytes.Buffer
+	if err := goTemplate.ExecuteTemplate(&buf, "descent", struct {
+		*Tables
+		Options
+		Procedures []procedure
+		StartFunc  string
+	}{t, opts, procedures(t), funcNames(t)[t.Symbol(t.Start).Name]}); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %v\n%s", err, buf.String())
+	}
+	return src, nil
+}
+
+// GeneratePseudo 生成递归下降分析器的伪代码 用于教学
+func GeneratePseudo(t *Tables) string {
+	var buf bytes.Buffer
+	_ = pseudoTemplate.Execute(&buf, struct {
+		*Tables
+		Procedures []procedure
+	}{t, procedures(t)})
+	return buf.String()
+}
+
+func init() {
+	template.Must(goTemplate.New("descent").Parse(`{{template "header" .}}
+{{template "runtime" .}}
+// rdParser 递归下降分析器的状态
+type rdParser struct {
+	tokens []Token
+	pos    int
+	action Action
+}
+
+// Parse 分析 tokens 最后的 TokEOF 可以省略 action 为 nil 时返回 *Node
+func Parse(tokens []Token, action Action) (interface{}, error) {
+	if action == nil {
+		action = buildNode
+	}
+	if len(tokens) == 0 || tokens[len(tokens)-1].Kind != TokEOF {
+		end := Token{Kind: TokEOF, Value: symbolNames[TokEOF]}
+		if len(tokens) > 0 {
+			last := tokens[len(tokens)-1]
+			end.Row, end.Column = last.Row, last.Column+len(last.Value)
+		}
+		tokens = append(tokens[:len(tokens):len(tokens)], end)
+	}
+	p := &rdParser{tokens: tokens, action: action}
+	v, err := p.{{.StartFunc}}()
+	if err != nil {
+		return nil, err
+	}
+	if _, err = p.expect(TokEOF); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// peek 当前的词法单元
+func (p *rdParser) peek() Token {
+	return p.tokens[p.pos]
+}
+
+// expect 匹配终结符 kind 结束符不会被读过
+func (p *rdParser) expect(kind int) (Token, error) {
+	t := p.tokens[p.pos]
+	if t.Kind != kind {
+		return t, p.fail(kind)
+	}
+	if kind != TokEOF {
+		p.pos++
+	}
+	return t, nil
+}
+
+// fail 当前的词法单元不是 expected 中的任何一个
+func (p *rdParser) fail(expected ...int) error {
+	var names []string
+	for _, kind := range expected {
+		names = append(names, symbolNames[kind])
+	}
+	return &SyntaxError{Token: p.peek(), Expected: names}
+}
+{{range .Procedures}}
+// {{.Func}} 分析 {{.Symbol.Display}}
+func (p *rdParser) {{.Func}}() (interface{}, error) {
+	switch p.peek().Kind {
+{{- range .Alternatives}}
+	case {{range $i, $s := .Lookahead}}{{if $i}}, {{end}}{{$s.Ident}}{{end}}:
+		// {{.Text}}
+{{- range $i, $s := .Right}}
+		v{{$i}}, err := {{if $s.Terminal}}p.expect({{$s.Symbol.Ident}}){{else}}p.{{$s.Func}}(){{end}}
+		if err != nil {
+			return nil, err
+		}
+{{- end}}
+		return p.action({{.Production}}, {{if .Right}}[]interface{}{ {{- range $i, $s := .Right}}{{if $i}}, {{end}}v{{$i}}{{end}}}{{else}}nil{{end}}), nil
+{{- end}}
+	}
+	return nil, p.fail({{range $i, $s := .Expected}}{{if $i}}, {{end}}{{$s.Ident}}{{end}})
+}
+{{end}}
+{{template "buildNode" .}}`))
+}
+
+var pseudoTemplate = template.Must(template.New("pseudo").Parse(`{{range .Procedures}}procedure {{.Symbol.Display}}()
+    switch lookahead
+{{- range .Alternatives}}
+    case {{range $i, $s := .Lookahead}}{{if $i}}, {{end}}'{{$s.Display}}'{{end}}:    -- {{.Text}}
+{{- range .Right}}
+        {{if .Terminal}}match('{{.Symbol.Display}}'){{else}}{{.Symbol.Display}}(){{end}}
+{{- end}}
+{{- if not .Right}}
+        skip
+{{- end}}
+{{- end}}
+    default:
+        error(expected {{range $i, $s := .Expected}}{{if $i}} or {{end}}'{{$s.Display}}'{{end}})
+    end
+end
+
+{{end -}}
+procedure main()
+    lookahead := first token
+    {{(.Symbol .Start).Display}}()
+    match('#')
+    accept
+end
+
+procedure match(t)
+    if lookahead = t then
+        lookahead := next token
+    else
+        error(expected t)
+end
+`))
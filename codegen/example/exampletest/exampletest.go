@@ -0,0 +1,134 @@
+/*
+Package exampletest 示例分析器 expr 和 exprrd 共用的测试
+
+两个示例由同一个文法生成 测试用的句子 期望的错误信息和求值的语义动作都放在这里
+各个示例只需要提供把源代码交给自己生成的 Parse 的函数
+*/
+package exampletest
+
+import (
+	"context"
+	"github.com/esonhugh/compiler/grammarLL1/rule"
+	"github.com/esonhugh/compiler/lexer"
+	"github.com/esonhugh/compiler/parser"
+	"os"
+	"strconv"
+	"testing"
+)
+
+// Grammar 示例文法 路径相对于示例的包目录
+const Grammar = "../expr/expr.grammar"
+
+// Start 示例文法的开始符号
+const Start = "E"
+
+// Corpus 与表驱动分析器对照的句子 包括错误的句子
+var Corpus = []string{"a", "a+b*c", "(a-b)/c", "a*(b+(c-d))", "a+", "(a", "a b", "+a", ""}
+
+// SyntaxErrors 错误的句子和生成的分析器应该给出的错误信息
+var SyntaxErrors = map[string]string{
+	"(a + b":  "line 1 col 7: expected ), found end of input",
+	"a + * b": "line 1 col 5: expected ( or i, found '*'",
+}
+
+// Values 用 Action 求值的句子和结果
+var Values = map[string]float64{"1+2*3": 7, "(1+2)*3": 9, "8-2-1": 5, "8/2/2": 2}
+
+// Token 词法单元和它在文法中对应的终结符 没有对应的终结符时 Terminal 为空
+type Token struct {
+	*lexer.Token
+	Terminal string
+}
+
+// Rules 读入示例文法
+func Rules(t *testing.T) *rule.Rule {
+	grammar, err := os.ReadFile(Grammar)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := rule.Parse(string(grammar))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// Tokens 词法分析 code 并用文法的 Matcher 找到每个词法单元对应的终结符
+func Tokens(t *testing.T, code string) []Token {
+	m := Rules(t).Matcher()
+	var res []Token
+	for _, tok := range lexer.Analyse(code) {
+		res = append(res, Token{Token: tok, Terminal: m.TerminalOf(tok)})
+	}
+	return res
+}
+
+// SameAsTableDriven 生成的分析器必须与表驱动的 LL(1) 分析器接受同样的句子 并且得到同样的语法树
+// parse 分析 code 并返回括号形式的语法树
+func SameAsTableDriven(t *testing.T, parse func(code string) (string, error)) {
+	r := Rules(t)
+	ll1, err := parser.New(parser.LL1, r.String(), Start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, code := range Corpus {
+		want, diagnostics := ll1.Parse(context.Background(), parser.FromString(code))
+		got, err := parse(code)
+		if (err == nil) != (len(diagnostics) == 0) {
+			t.Errorf("%q: generated parser error %v, table-driven %v", code, err, diagnostics)
+			continue
+		}
+		if err == nil && got != want.Format(r.Names) {
+			t.Errorf("%q: got %s, want %s", code, got, want.Format(r.Names))
+		}
+	}
+}
+
+// SyntaxError 检查 SyntaxErrors 中每个句子的错误信息
+func SyntaxError(t *testing.T, parse func(code string) (string, error)) {
+	for code, want := range SyntaxErrors {
+		if _, err := parse(code); err == nil || err.Error() != want {
+			t.Errorf("%q: unexpected error %v", code, err)
+		}
+	}
+}
+
+// rest E' 和 T' 的值 把左操作数变成结果的函数
+type rest func(float64) float64
+
+// Action 求值的语义动作 production 为产生式的文本形式 lexeme 取出终结符的值对应的源代码
+func Action(production string, c []interface{}, lexeme func(interface{}) string) interface{} {
+	switch production {
+	case "E->TE'", "T->FT'":
+		return c[1].(rest)(c[0].(float64))
+	case "E'->+TE'":
+		return rest(func(x float64) float64 { return c[2].(rest)(x + c[1].(float64)) })
+	case "E'->-TE'":
+		return rest(func(x float64) float64 { return c[2].(rest)(x - c[1].(float64)) })
+	case "T'->*FT'":
+		return rest(func(x float64) float64 { return c[2].(rest)(x * c[1].(float64)) })
+	case "T'->/FT'":
+		return rest(func(x float64) float64 { return c[2].(rest)(x / c[1].(float64)) })
+	case "E'->ε", "T'->ε":
+		return rest(func(x float64) float64 { return x })
+	case "F->(E)":
+		return c[1]
+	case "F->i":
+		v, _ := strconv.ParseFloat(lexeme(c[0]), 64)
+		return v
+	}
+	panic("unknown production " + production)
+}
+
+// Evaluate 检查 Values 中每个句子求值的结果 eval 用 Action 分析 code
+func Evaluate(t *testing.T, eval func(code string) (interface{}, error)) {
+	for code, want := range Values {
+		got, err := eval(code)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.(float64) != want {
+			t.Errorf("%s: got %v, want %v", code, got, want)
+		}
+	}
+}
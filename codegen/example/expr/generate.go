@@ -0,0 +1,4 @@
+package expr
+
+// 修改 expr.grammar 后运行 go generate 重新生成 parser.go
+//go:generate go run ../../../cmd/parsergen -grammar expr.grammar -start E -package expr -o parser.go
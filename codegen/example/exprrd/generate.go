@@ -0,0 +1,4 @@
+package exprrd
+
+// 与 ../expr 使用同一个文法 修改文法后运行 go generate 重新生成 parser.go
+//go:generate go run ../../../cmd/parsergen -grammar ../expr/expr.grammar -start E -package exprrd -style descent -o parser.go
@@ -0,0 +1,47 @@
+package exprrd
+
+import (
+	"github.com/esonhugh/compiler/codegen/example/exampletest"
+	"testing"
+)
+
+// tokensOf 把词法单元对应到生成的终结符常量 没有对应的终结符时 Kind 为 -1
+func tokensOf(t *testing.T, code string) []Token {
+	var res []Token
+	for _, tok := range exampletest.Tokens(t, code) {
+		kind, ok := Lookup(tok.Terminal)
+		if !ok {
+			kind = -1
+		}
+		res = append(res, Token{Kind: kind, Value: tok.Value, Row: tok.Row, Column: tok.Column})
+	}
+	return res
+}
+
+// tree 分析 code 得到括号形式的语法树
+func tree(t *testing.T) func(code string) (string, error) {
+	return func(code string) (string, error) {
+		n, err := Parse(tokensOf(t, code), nil)
+		if err != nil {
+			return "", err
+		}
+		return n.(*Node).String(), nil
+	}
+}
+
+func TestSameAsTableDriven(t *testing.T) {
+	exampletest.SameAsTableDriven(t, tree(t))
+}
+
+func TestSyntaxError(t *testing.T) {
+	exampletest.SyntaxError(t, tree(t))
+}
+
+func TestActions(t *testing.T) {
+	action := func(p int, c []interface{}) interface{} {
+		return exampletest.Action(Production(p), c, func(v interface{}) string { return v.(Token).Value })
+	}
+	exampletest.Evaluate(t, func(code string) (interface{}, error) {
+		return Parse(tokensOf(t, code), action)
+	})
+}
@@ -0,0 +1,276 @@
+package codegen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strings"
+	"text/template"
+)
+
+// Options 生成代码的选项
+type Options struct {
+	Package string // Package 生成的包名
+	Source  string // Source 文法文件名 写在生成代码的第一行
+}
+
+// GenerateGo 生成一个独立的 Go 分析器 只依赖标准库
+// 包含终结符和非终结符常量 产生式和分析表字面量 表驱动的分析程序 以及语义动作钩子
+func GenerateGo(t *Tables, opts Options) ([]byte, error) {
+	if opts.Package == "" {
+		return nil, fmt.Errorf("package name is required")
+	}
+	var buf bytes.Buffer
+	if err := goTemplate.ExecuteTemplate(&buf, "table", struct {
+		*Tables
+		Options
+		TableType string
+	}{t, opts, tableType(len(t.Productions))}); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("format generated code: %v\n%s", err, buf.String())
+	}
+	return src, nil
+}
+
+// tableType 能放下全部产生式编号的最小整数类型
+func tableType(n int) string {
+	switch {
+	case n < 1<<7:
+		return "int8"
+	case n < 1<<15:
+		return "int16"
+	}
+	return "int32"
+}
+
+var goTemplate = template.Must(template.New("go").Funcs(template.FuncMap{
+	"lines": func(s string) []string { return strings.Split(strings.TrimSpace(s), "\n") },
+	"ident": func(t *Tables, symbol int) string { return t.Symbol(symbol).Ident },
+	"join": func(xs []int) string {
+		var res []string
+		for _, x := range xs {
+			res = append(res, fmt.Sprint(x))
+		}
+		return strings.Join(res, ", ")
+	},
+}).Parse(`{{define "header"}}// Code generated by parsergen{{if .Source}} from {{.Source}}{{end}}. DO NOT EDIT.
+
+// Package {{.Package}} 由 parsergen 生成的 LL(1) 分析器 文法为
+//
+{{- range lines .Rules}}
+//	{{.}}
+{{- end}}
+package {{.Package}}
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 终结符 即 Token.Kind
+const (
+{{- range $i, $s := .Terminals}}
+	{{$s.Ident}}{{if eq $i 0}} = iota{{end}} // {{$s.Display}}
+{{- end}}
+)
+
+// 非终结符
+const (
+{{- range $i, $s := .Nonterminals}}
+	{{$s.Ident}}{{if eq $i 0}} = iota + {{len $.Terminals}}{{end}} // {{$s.Display}}
+{{- end}}
+)
+
+// Start 开始符号
+const Start = {{ident .Tables .Start}}
+
+// terminalCount 终结符的个数
+const terminalCount = {{len .Terminals}}
+
+// symbolNames 每个符号的显示形式
+var symbolNames = [...]string{
+{{- range .Terminals}}
+	{{printf "%q" .Display}},
+{{- end}}
+{{- range .Nonterminals}}
+	{{printf "%q" .Display}},
+{{- end}}
+}
+
+// terminalNames 文法中的终结符 用于把词法单元对应到 Token.Kind
+var terminalNames = map[string]int{
+{{- range .Terminals}}
+	{{printf "%q" .Name}}: {{.Ident}},
+{{- end}}
+}
+
+// productions 产生式 右部为符号编号 空产生式的右部为空
+var productions = [...]struct {
+	left  int
+	right []int
+	text  string
+}{
+{{- range .Productions}}
+	{ {{- ident $.Tables .Left}}, {{if .Right}}[]int{ {{- range $i, $x := .Right}}{{if $i}}, {{end}}{{ident $.Tables $x}}{{end}}}{{else}}nil{{end}}, {{printf "%q" .Text}}},
+{{- end}}
+}
+{{end}}
+
+{{define "runtime"}}// Token 词法单元 Kind 为终结符常量
+type Token struct {
+	Kind   int
+	Value  string
+	Row    int
+	Column int
+}
+
+// Node 没有设置语义动作时构造的语法树结点
+type Node struct {
+	Symbol   string
+	Token    *Token
+	Children []*Node
+}
+
+// String 括号形式 E(T(F(i)) E'(ε))
+func (n *Node) String() string {
+	if len(n.Children) == 0 {
+		return n.Symbol
+	}
+	var children []string
+	for _, c := range n.Children {
+		children = append(children, c.String())
+	}
+	return n.Symbol + "(" + strings.Join(children, " ") + ")"
+}
+
+// Action 语义动作 产生式 production 的右部全部分析完后调用
+// children 为右部各个符号的值 终结符的值为 Token 非终结符的值为它的产生式的语义动作的结果
+type Action func(production int, children []interface{}) interface{}
+
+// SyntaxError 语法错误 Expected 为可以接受的终结符
+type SyntaxError struct {
+	Token    Token
+	Expected []string
+}
+
+func (e *SyntaxError) Error() string {
+	found := "end of input"
+	if e.Token.Kind != TokEOF {
+		found = "'" + e.Token.Value + "'"
+	}
+	return fmt.Sprintf("line %d col %d: expected %s, found %s", e.Token.Row, e.Token.Column, strings.Join(e.Expected, " or "), found)
+}
+
+// Lookup 文法中的终结符对应的 Token.Kind
+func Lookup(terminal string) (int, bool) {
+	kind, ok := terminalNames[terminal]
+	return kind, ok
+}
+
+// SymbolName 符号的显示形式
+func SymbolName(symbol int) string {
+	return symbolNames[symbol]
+}
+
+// Production 第 p 个产生式的文本形式 用于编写语义动作
+func Production(p int) string {
+	return productions[p].text
+}
+{{end}}
+
+{{define "buildNode"}}// buildNode 默认的语义动作 构造语法树 空产生式得到 ε 叶子
+func buildNode(p int, children []interface{}) interface{} {
+	n := &Node{Symbol: symbolNames[productions[p].left]}
+	for _, c := range children {
+		switch v := c.(type) {
+		case Token:
+			n.Children = append(n.Children, &Node{Symbol: symbolNames[v.Kind], Token: &v})
+		case *Node:
+			n.Children = append(n.Children, v)
+		}
+	}
+	if len(n.Children) == 0 {
+		n.Children = append(n.Children, &Node{Symbol: "ε"})
+	}
+	return n
+}
+{{end}}
+
+{{define "table"}}{{template "header" .}}
+// parseTable 分析表 parseTable[A-terminalCount][a] 为产生式编号 -1 表示出错
+var parseTable = [...][terminalCount]{{.TableType}}{
+{{- range $i, $row := .Table}}
+	{ {{- join $row}}}, // {{(index $.Nonterminals $i).Display}}
+{{- end}}
+}
+
+{{template "runtime" .}}
+// Parse 分析 tokens 最后的 TokEOF 可以省略 action 为 nil 时返回 *Node
+func Parse(tokens []Token, action Action) (interface{}, error) {
+	if action == nil {
+		action = buildNode
+	}
+	if len(tokens) == 0 || tokens[len(tokens)-1].Kind != TokEOF {
+		end := Token{Kind: TokEOF, Value: symbolNames[TokEOF]}
+		if len(tokens) > 0 {
+			last := tokens[len(tokens)-1]
+			end.Row, end.Column = last.Row, last.Column+len(last.Value)
+		}
+		tokens = append(tokens[:len(tokens):len(tokens)], end)
+	}
+	// 分析栈中的负数 -(p+1) 表示产生式 p 的右部已经分析完 需要执行语义动作
+	stack := []int{TokEOF, Start}
+	var values []interface{}
+	pos := 0
+	for len(stack) > 0 {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		current := tokens[pos]
+		switch {
+		case top < 0:
+			p := -top - 1
+			n := len(values) - len(productions[p].right)
+			children := append([]interface{}(nil), values[n:]...)
+			values = append(values[:n], action(p, children))
+		case top < terminalCount:
+			if current.Kind != top {
+				return nil, &SyntaxError{Token: current, Expected: []string{symbolNames[top]}}
+			}
+			if top == TokEOF {
+				return values[0], nil
+			}
+			values = append(values, current)
+			pos++
+		default:
+			p := -1
+			if current.Kind >= 0 && current.Kind < terminalCount {
+				p = int(parseTable[top-terminalCount][current.Kind])
+			}
+			if p < 0 {
+				return nil, &SyntaxError{Token: current, Expected: expected(top)}
+			}
+			stack = append(stack, -(p + 1))
+			right := productions[p].right
+			for i := len(right) - 1; i >= 0; i-- {
+				stack = append(stack, right[i])
+			}
+		}
+	}
+	return nil, &SyntaxError{Token: tokens[pos], Expected: []string{symbolNames[TokEOF]}}
+}
+
+// expected 非终结符 symbol 可以接受的终结符
+func expected(symbol int) []string {
+	var res []string
+	for kind, p := range parseTable[symbol-terminalCount] {
+		if p >= 0 {
+			res = append(res, symbolNames[kind])
+		}
+	}
+	return res
+}
+
+{{template "buildNode" .}}{{end}}`))
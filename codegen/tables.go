@@ -0,0 +1,117 @@
+/*
+Package codegen 由文法生成独立的分析器源代码
+
+先把 LL(1) 分析表导出为 Tables 再按模板生成 Go 代码 生成的分析器运行时不再分析文法
+*/
+package codegen
+
+import (
+	"errors"
+	"github.com/esonhugh/compiler/grammarLL1"
+	"github.com/esonhugh/compiler/grammarLL1/rule"
+	"strings"
+)
+
+// Symbol 文法符号 Name 为文法中的字符 Ident 为生成代码中的常量名 Display 为 %name 声明的显示形式
+type Symbol struct {
+	Name    string `json:"name"`
+	Ident   string `json:"ident"`
+	Display string `json:"display"`
+}
+
+// Production 产生式 Left 和 Right 为符号编号 终结符在前 非终结符在后 空产生式的 Right 为空
+type Production struct {
+	Left  int    `json:"left"`
+	Right []int  `json:"right"`
+	Text  string `json:"text"`
+}
+
+// Tables 导出的 LL(1) 分析表
+// 终结符的编号就是 Token.Kind 第 0 个终结符是结束符 #
+// Table[A][a] 为非终结符 A 遇到终结符 a 时使用的产生式编号 -1 表示出错
+type Tables struct {
+	Rules        string       `json:"rules"`
+	Start        int          `json:"start"`
+	Terminals    []Symbol     `json:"terminals"`
+	Nonterminals []Symbol     `json:"nonterminals"`
+	Productions  []Production `json:"productions"`
+	Table        [][]int      `json:"table"`
+}
+
+// terminalNames 常见符号的常量名
+var terminalNames = map[byte]string{
+	'+': "Plus", '-': "Minus", '*': "Star", '/': "Slash", '%': "Percent",
+	'(': "LParen", ')': "RParen", '[': "LBracket", ']': "RBracket", '{': "LBrace", '}': "RBrace",
+	',': "Comma", ';': "Semicolon", ':': "Colon", '.': "Dot", '?': "Question",
+	'=': "Assign", '<': "Less", '>': "Greater", '!': "Not", '|': "Or", '^': "Caret", '~': "Tilde",
+	'@': "At", '$': "Dollar", '\'': "Quote", '"': "DoubleQuote", '\\': "Backslash",
+}
+
+// Export 检查文法并导出 LL(1) 分析表 文法不是 LL(1) 的时候返回冲突报告
+func Export(rules string, start string) (*Tables, error) {
+	r, symbolTable, conflicts, err := grammarLL1.BuildTable(rules, start)
+	if err != nil {
+		return nil, err
+	}
+	if !conflicts.IsLL1() {
+		return nil, errors.New("grammar is not LL(1):\n" + conflicts.String())
+	}
+	t := &Tables{Rules: r.String()}
+	ids := make(map[string]int)
+
+	t.Terminals = append(t.Terminals, Symbol{Name: grammarLL1.EndToken, Ident: "TokEOF", Display: grammarLL1.EndToken})
+	symbols := r.Symbols()
+	for _, name := range symbols.Terminals[:len(symbols.Terminals)-1] {
+		ident := "Tok" + strings.ToUpper(name)
+		if n, ok := terminalNames[name[0]]; ok {
+			ident = "Tok" + n
+		} else if name[0] >= '0' && name[0] <= '9' {
+			ident = "TokDigit" + name
+		}
+		t.Terminals = append(t.Terminals, Symbol{Name: name, Ident: ident, Display: name})
+	}
+	for i, s := range t.Terminals {
+		ids[s.Name] = i
+	}
+	for _, name := range r.Nonterminals() {
+		ids[name] = len(t.Terminals) + len(t.Nonterminals)
+		t.Nonterminals = append(t.Nonterminals, Symbol{Name: name, Ident: "Nt" + name, Display: r.Name(name)})
+	}
+	t.Start = ids[start]
+
+	index := make(map[rule.Formula]int)
+	for _, f := range r.Formulas() {
+		p := Production{Left: ids[f.Left], Text: r.Name(f.Left + "->" + f.Right)}
+		if f.Right != "&" {
+			for i := 0; i < len(f.Right); i++ {
+				p.Right = append(p.Right, ids[f.Right[i:i+1]])
+			}
+		}
+		index[*f] = len(t.Productions)
+		t.Productions = append(t.Productions, p)
+	}
+	for _, nt := range t.Nonterminals {
+		row := make([]int, len(t.Terminals))
+		for i, terminal := range t.Terminals {
+			row[i] = -1
+			if f := symbolTable.Get(nt.Name, terminal.Name); f != nil {
+				row[i] = index[*f]
+			}
+		}
+		t.Table = append(t.Table, row)
+	}
+	return t, nil
+}
+
+// IsTerminal 编号为 symbol 的符号是否是终结符
+func (t *Tables) IsTerminal(symbol int) bool {
+	return symbol < len(t.Terminals)
+}
+
+// Symbol 编号为 symbol 的符号
+func (t *Tables) Symbol(symbol int) Symbol {
+	if t.IsTerminal(symbol) {
+		return t.Terminals[symbol]
+	}
+	return t.Nonterminals[symbol-len(t.Terminals)]
+}
@@ -0,0 +1,68 @@
+package dot
+
+import (
+	"fmt"
+	"github.com/esonhugh/compiler/grammarLR/item"
+	"github.com/esonhugh/compiler/lexer"
+	"strings"
+)
+
+// Collection LR 项目集族 每个状态列出全部项目 边为 GOTO
+// 含有 S'->S· 的接受状态用双线框表示
+func Collection(c *item.Collection) string {
+	g := NewGraph("lr", "rankdir=LR", "node [shape=box, fontname=monospace]")
+	for _, state := range c.States {
+		lines := []string{fmt.Sprintf("I%d", state.Index)}
+		if len(state.Merged) > 1 {
+			var merged []string
+			for _, m := range state.Merged {
+				merged = append(merged, fmt.Sprintf("I%d", m))
+			}
+			lines[0] += " (" + strings.Join(merged, " ") + ")"
+		}
+		accept := false
+		for _, i := range state.Items {
+			lines = append(lines, c.Grammar.ItemString(i))
+			if i.Formula == 0 && c.Grammar.Next(i) == "" {
+				accept = true
+			}
+		}
+		var attrs []string
+		if accept {
+			attrs = append(attrs, "peripheries=2")
+		}
+		g.Node(fmt.Sprintf("I%d", state.Index), strings.Join(lines, "\n"), attrs...)
+	}
+	for _, state := range c.States {
+		for _, x := range c.Grammar.Symbols(state.Items) {
+			g.Edge(fmt.Sprintf("I%d", state.Index), fmt.Sprintf("I%d", state.Goto[x]), c.Grammar.Name(x))
+		}
+	}
+	return g.String()
+}
+
+// DFA 词法分析的自动机 接受状态用双圈表示 并标出词法单元类型
+func DFA(d *lexer.DFA) string {
+	g := NewGraph(d.Name, "rankdir=LR", "node [shape=circle]")
+	g.Node("start", "", "shape=point")
+	name := func(s string) string {
+		if s == "" {
+			return "q0"
+		}
+		return s
+	}
+	for _, s := range d.States() {
+		if typ, ok := d.Accept[s]; ok {
+			g.Node(name(s), name(s)+"\n"+strings.TrimSpace(typ.String()), "shape=doublecircle")
+		} else {
+			g.Node(name(s), name(s))
+		}
+	}
+	g.Edge("start", name(""), "")
+	for _, s := range d.States() {
+		for _, c := range d.Inputs(s) {
+			g.Edge(name(s), name(d.Edges[s][c]), c)
+		}
+	}
+	return g.String()
+}
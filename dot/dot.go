@@ -0,0 +1,67 @@
+/*
+Package dot 输出 Graphviz DOT 格式的图 包括语法树 语法森林 文法依赖图以及各种自动机
+
+结点按遍历顺序编号 边按固定顺序输出 相同的输入总是得到相同的结果
+非终结符按文法的 %name 声明显示 没有声明的符号保持原样 & 显示为 ε
+*/
+package dot
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Graph 有向图 按加入的顺序输出结点和边
+type Graph struct {
+	Name  string
+	Attrs []string // Attrs 图的属性 例如 rankdir=LR
+	nodes []string
+	edges []string
+}
+
+// NewGraph 创建一个空的有向图
+func NewGraph(name string, attrs ...string) *Graph {
+	return &Graph{Name: name, Attrs: attrs}
+}
+
+// Node 加入一个结点 attrs 为 key=value 形式的其他属性
+func (g *Graph) Node(id string, label string, attrs ...string) {
+	g.nodes = append(g.nodes, fmt.Sprintf("%s [%s]", Quote(id), strings.Join(append([]string{"label=" + Quote(label)}, attrs...), ", ")))
+}
+
+// Edge 加入一条边 label 为空时不显示标签
+func (g *Graph) Edge(from, to string, label string, attrs ...string) {
+	if label != "" {
+		attrs = append([]string{"label=" + Quote(label)}, attrs...)
+	}
+	edge := Quote(from) + " -> " + Quote(to)
+	if len(attrs) != 0 {
+		edge += " [" + strings.Join(attrs, ", ") + "]"
+	}
+	g.edges = append(g.edges, edge)
+}
+
+// String DOT 源代码
+func (g *Graph) String() string {
+	var build strings.Builder
+	build.WriteString("digraph " + Quote(g.Name) + " {\n")
+	for _, a := range g.Attrs {
+		build.WriteString("\t" + a + ";\n")
+	}
+	for _, n := range g.nodes {
+		build.WriteString("\t" + n + ";\n")
+	}
+	for _, e := range g.edges {
+		build.WriteString("\t" + e + ";\n")
+	}
+	build.WriteString("}\n")
+	return build.String()
+}
+
+// Quote 转义为 DOT 的字符串 换行写作 \n
+func Quote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
@@ -0,0 +1,121 @@
+package dot
+
+import (
+	"context"
+	"github.com/esonhugh/compiler/grammarEarley"
+	"github.com/esonhugh/compiler/grammarLL1/rule"
+	"github.com/esonhugh/compiler/grammarLR/item"
+	"github.com/esonhugh/compiler/lexer"
+	"github.com/esonhugh/compiler/parser"
+	"strings"
+	"testing"
+)
+
+func TestTree(t *testing.T) {
+	const rules = "%name G E'\nE->TG\nG->+TG|&\nT->i"
+	p, err := parser.New(parser.LL1, rules, "E")
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, diagnostics := p.Parse(context.Background(), parser.FromString("a"))
+	if len(diagnostics) != 0 {
+		t.Fatal(diagnostics)
+	}
+	want := `digraph "tree" {
+	node [shape=plaintext];
+	"n0" [label="E"];
+	"n1" [label="T"];
+	"n2" [label="i\na", fontcolor=darkgreen];
+	"n3" [label="E'"];
+	"n4" [label="ε"];
+	"n0" -> "n1";
+	"n1" -> "n2";
+	"n0" -> "n3";
+	"n3" -> "n4";
+}
+`
+	if got := Tree(root, rule.MustParse(rules).Names); got != want {
+		t.Fatalf("got\n%s", got)
+	}
+}
+
+func TestForest(t *testing.T) {
+	forest, ok := grammarEarley.Analyze(lexer.Analyse("a+b+c"), "E->E+E|i", "E")
+	if !ok {
+		t.Fatal("a+b+c should be accepted")
+	}
+	got := Forest(forest, nil)
+	if !strings.Contains(got, "color=red") || strings.Count(got, "shape=point") < 2 {
+		t.Fatalf("ambiguous node should be highlighted\n%s", got)
+	}
+	if got != Forest(forest, nil) {
+		t.Fatal("output should be deterministic")
+	}
+}
+
+func TestGrammar(t *testing.T) {
+	r := rule.MustParse("%name G E'\nE->TG\nG->+TG|&\nT->(E)|iX")
+	want := `digraph "grammar" {
+	"E" [label="E", shape=doublecircle];
+	"G" [label="E'", shape=circle];
+	"T" [label="T", shape=circle];
+	"X" [label="X", shape=circle, style=dashed];
+	"E" -> "T" [label="TE'"];
+	"E" -> "G" [label="TE'"];
+	"G" -> "T" [label="+TE'"];
+	"G" -> "G" [label="+TE'"];
+	"T" -> "E" [label="(E)"];
+	"T" -> "X" [label="iX"];
+}
+`
+	if got := Grammar(r, "E"); got != want {
+		t.Fatalf("got\n%s", got)
+	}
+}
+
+func TestCollection(t *testing.T) {
+	r := rule.MustParse("E->E+T|T\nT->i")
+	g, err := item.Augment(r, "E")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `digraph "lr" {
+	rankdir=LR;
+	node [shape=box, fontname=monospace];
+	"I0" [label="I0\nE'->·E\nE->·E+T\nE->·T\nT->·i"];
+	"I1" [label="I1\nT->i·"];
+	"I2" [label="I2\nE'->E·\nE->E·+T", peripheries=2];
+	"I3" [label="I3\nE->T·"];
+	"I4" [label="I4\nE->E+·T\nT->·i"];
+	"I5" [label="I5\nE->E+T·"];
+	"I0" -> "I1" [label="i"];
+	"I0" -> "I2" [label="E"];
+	"I0" -> "I3" [label="T"];
+	"I2" -> "I4" [label="+"];
+	"I4" -> "I1" [label="i"];
+	"I4" -> "I5" [label="T"];
+}
+`
+	if got := Collection(item.LR0(g)); got != want {
+		t.Fatalf("got\n%s", got)
+	}
+	lalr := Collection(item.LALR(item.LR1(g)))
+	if !strings.Contains(lalr, ", #") {
+		t.Fatalf("LR(1) items should show the lookahead\n%s", lalr)
+	}
+}
+
+func TestDFA(t *testing.T) {
+	got := DFA(lexer.OperatorDFA)
+	for _, want := range []string{
+		`"start" -> "q0";`,
+		`"q0" -> "<" [label="<"];`,
+		`"<" -> "<>" [label=">"];`,
+		`"<>" [label="<>\noperator", shape=doublecircle];`,
+		`":" [label=":"];`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("missing %s\n%s", want, got)
+		}
+	}
+}
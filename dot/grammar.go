@@ -0,0 +1,47 @@
+package dot
+
+import (
+	"github.com/esonhugh/compiler/grammarLL1/rule"
+	"github.com/esonhugh/compiler/grammarLL1/util"
+	"strings"
+)
+
+// Grammar 文法依赖图 A 的某个产生式右部出现 B 时有一条 A 到 B 的边
+// 边的标签为出现 B 的全部右部 开始符号用双圈表示 没有产生式的非终结符用虚线表示 结点按 %name 声明的名字显示
+func Grammar(r *rule.Rule, start string) string {
+	g := NewGraph("grammar")
+	undefined := make(map[string]bool)
+	for _, left := range r.Nonterminals() {
+		if left == start {
+			g.Node(left, r.Name(left), "shape=doublecircle")
+		} else {
+			g.Node(left, r.Name(left), "shape=circle")
+		}
+	}
+	for _, left := range r.Nonterminals() {
+		var targets []string
+		rights := make(map[string][]string)
+		for _, right := range r.Rules[left] {
+			seen := make(map[string]bool)
+			for i := 0; i < len(right); i++ {
+				c := right[i : i+1]
+				if util.IsTerminal(right[i]) || seen[c] {
+					continue
+				}
+				seen[c] = true
+				if _, ok := rights[c]; !ok {
+					targets = append(targets, c)
+				}
+				rights[c] = append(rights[c], r.Name(right))
+			}
+		}
+		for _, to := range targets {
+			if _, ok := r.Rules[to]; !ok && !undefined[to] {
+				undefined[to] = true
+				g.Node(to, r.Name(to), "shape=circle", "style=dashed")
+			}
+			g.Edge(left, to, strings.Join(rights[to], " | "))
+		}
+	}
+	return g.String()
+}
@@ -0,0 +1,68 @@
+package dot
+
+import (
+	"fmt"
+	"github.com/esonhugh/compiler/tree"
+	"github.com/esonhugh/compiler/util/transfer"
+)
+
+// Tree 语法树 叶子结点同时显示匹配到的词法单元 任何分析器得到的 tree.Node 都可以使用
+// names 为文法的 Names 非终结符按它显示 可以为 nil
+func Tree(root *tree.Node, names map[string]string) string {
+	g := NewGraph("tree", "node [shape=plaintext]")
+	count := 0
+	var visit func(n *tree.Node)
+	visit = func(n *tree.Node) {
+		id := fmt.Sprintf("n%d", count)
+		count++
+		label := tree.Name(n.Symbol, names)
+		switch {
+		case n.Token != nil && n.Token.Value != n.Symbol:
+			g.Node(id, label+"\n"+n.Token.Value, "fontcolor=darkgreen")
+		case n.Token != nil:
+			g.Node(id, label, "fontcolor=darkgreen")
+		default:
+			g.Node(id, label)
+		}
+		for _, c := range n.Children {
+			g.Edge(id, fmt.Sprintf("n%d", count), "")
+			visit(c)
+		}
+	}
+	if root != nil {
+		visit(root)
+	}
+	return g.String()
+}
+
+// Forest 共享压缩语法森林 符号结点为椭圆 压缩结点为点 有多种推导的结点标为红色 names 与 Tree 相同
+func Forest(f *tree.Forest, names map[string]string) string {
+	g := NewGraph("forest", "ordering=out")
+	ids := make(map[*tree.ForestNode]string)
+	for i, n := range f.Nodes {
+		ids[n] = fmt.Sprintf("n%d", i)
+	}
+	for _, n := range f.Nodes {
+		label := fmt.Sprintf("%s [%d,%d]", tree.Name(n.Symbol, names), n.Start, n.End)
+		var attrs []string
+		if n.Token != nil {
+			label = tree.Name(n.Symbol, names) + "\n" + n.Token.Value
+			attrs = append(attrs, "shape=box")
+		}
+		if len(n.Packed) > 1 {
+			attrs = append(attrs, "color=red")
+		}
+		g.Node(ids[n], label, attrs...)
+	}
+	for _, n := range f.Nodes {
+		for j, p := range n.Packed {
+			packed := fmt.Sprintf("%s_%d", ids[n], j)
+			g.Node(packed, "", "shape=point")
+			g.Edge(ids[n], packed, transfer.TransferWith(p.Formula.Left+"->"+p.Formula.Right, names))
+			for _, c := range p.Children {
+				g.Edge(packed, ids[c], "")
+			}
+		}
+	}
+	return g.String()
+}
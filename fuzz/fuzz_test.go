@@ -0,0 +1,148 @@
+package fuzz
+
+import (
+	"context"
+	"github.com/esonhugh/compiler/grammar"
+	"github.com/esonhugh/compiler/grammarLL1/rule"
+	"github.com/esonhugh/compiler/parser"
+	"github.com/esonhugh/compiler/tree"
+	"strings"
+	"testing"
+)
+
+const expression = "E->E+T|E-T|T\nT->T*F|T/F|F\nF->(E)|i"
+
+func newGenerator(t *testing.T, rules, start string, opts Options) *Generator {
+	g, err := New(rule.MustParse(rules), start, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func depth(n *tree.Node) int {
+	d := 0
+	for _, c := range n.Children {
+		if h := depth(c); h > d {
+			d = h
+		}
+	}
+	return d + 1
+}
+
+func TestDepth(t *testing.T) {
+	for _, max := range []int{1, 4, 6, 10} {
+		g := newGenerator(t, expression, "E", Options{MaxDepth: max, Seed: int64(max)})
+		for i := 0; i < 100; i++ {
+			n := g.Tree()
+			// 最矮的语法树 E(T(F(i))) 加上叶子有 4 层
+			if d := depth(n); d > max+1 && d > 4 {
+				t.Fatalf("max depth %d: got depth %d %s", max, d, n.String())
+			}
+		}
+	}
+}
+
+func TestWeights(t *testing.T) {
+	g := newGenerator(t, expression, "E", Options{Seed: 1, Weights: map[rule.Formula]float64{
+		{Left: "E", Right: "E+T"}: 0,
+		{Left: "E", Right: "E-T"}: 0,
+		{Left: "F", Right: "(E)"}: 10,
+	}})
+	for i := 0; i < 100; i++ {
+		if s := strings.Join(g.Sentence(), ""); strings.ContainsAny(s, "+-") {
+			t.Fatalf("productions with weight 0 should not be used: %s", s)
+		}
+	}
+}
+
+func TestCoverage(t *testing.T) {
+	g := newGenerator(t, expression, "E", Options{Seed: 1, Coverage: true})
+	for i := 0; i < 5 && len(g.Unused()) != 0; i++ {
+		g.Sentence()
+	}
+	if used, total := g.Coverage(); used != total {
+		t.Fatalf("coverage %d/%d, unused %v", used, total, g.Unused())
+	}
+}
+
+func TestDeterministic(t *testing.T) {
+	a := newGenerator(t, expression, "E", Options{Seed: 42})
+	b := newGenerator(t, expression, "E", Options{Seed: 42})
+	for i := 0; i < 20; i++ {
+		if x, y := a.Tree().String(), b.Tree().String(); x != y {
+			t.Fatalf("same seed gives %s and %s", x, y)
+		}
+	}
+	if _, err := New(rule.MustParse("E->E+E"), "E", Options{}); err == nil {
+		t.Fatal("E derives no sentence")
+	}
+}
+
+func TestMutate(t *testing.T) {
+	g := newGenerator(t, expression, "E", Options{Seed: 7})
+	kinds := make(map[string]bool)
+	for i := 0; i < 50; i++ {
+		m, ok := g.Invalid(20)
+		if !ok {
+			t.Fatal("no invalid sentence found")
+		}
+		kinds[m.Kind] = true
+		if Accepts(g.Rules, "E", m.Sentence) || !Accepts(g.Rules, "E", m.Original) {
+			t.Fatalf("%s %v -> %v", m.Kind, m.Original, m.Sentence)
+		}
+	}
+	if len(kinds) != 3 {
+		t.Fatalf("expect all kinds of mutation, got %v", kinds)
+	}
+}
+
+// TestBackends 生成的句子必须被每个后端接受 并且得到同样的语法树 变异得到的句子必须被每个后端拒绝
+func TestBackends(t *testing.T) {
+	for rules, start := range map[string]string{grammar.Rules: "E", expression: "E"} {
+		g := newGenerator(t, rules, start, Options{Seed: 1, Coverage: true})
+		var names []string
+		var parsers []parser.Parser
+		for _, name := range parser.Backends() {
+			p, err := parser.New(name, rules, start)
+			if err != nil {
+				continue
+			}
+			names = append(names, name)
+			parsers = append(parsers, p)
+		}
+		if len(parsers) < 5 {
+			t.Fatalf("only %v can parse %q", names, rules)
+		}
+		for i := 0; i < 200; i++ {
+			n := g.Tree()
+			var sentence []string
+			for _, leaf := range n.Leaves() {
+				sentence = append(sentence, leaf.Symbol)
+			}
+			code := Code(g.Rules, sentence)
+			for j, p := range parsers {
+				root, diagnostics := p.Parse(context.Background(), parser.FromString(code))
+				if len(diagnostics) != 0 {
+					t.Fatalf("%s rejects %q: %v", names[j], code, diagnostics)
+				}
+				// 算符优先后端的语法树省略了单产生式 只检查是否接受
+				if names[j] != parser.OperatorPrec && root.String() != n.String() {
+					t.Fatalf("%s: %q parsed as %s, generated %s", names[j], code, root.String(), n.String())
+				}
+			}
+		}
+		for i := 0; i < 100; i++ {
+			m, ok := g.Invalid(20)
+			if !ok {
+				t.Fatal("no invalid sentence found")
+			}
+			code := Code(g.Rules, m.Sentence)
+			for j, p := range parsers {
+				if _, diagnostics := p.Parse(context.Background(), parser.FromString(code)); len(diagnostics) == 0 {
+					t.Fatalf("%s accepts %q (%s of %q)", names[j], code, m.Kind, Code(g.Rules, m.Original))
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,237 @@
+/*
+Package fuzz 由文法随机生成句子 用于测试词法分析器和各个语法分析器
+
+Generator 从开始符号随机推导出句子 可以控制推导深度 给产生式加权
+以及优先选择还没有用过的产生式以覆盖全部产生式
+Mutate 对合法的句子插入 删除或交换词法单元 得到几乎合法的错误句子
+*/
+package fuzz
+
+import (
+	"errors"
+	"github.com/esonhugh/compiler/grammarLL1/rule"
+	"github.com/esonhugh/compiler/grammarLL1/util"
+	"github.com/esonhugh/compiler/lexer"
+	"github.com/esonhugh/compiler/tree"
+	"math"
+	"math/rand"
+	"strings"
+)
+
+// DefaultMaxDepth 没有指定时语法树的最大深度
+const DefaultMaxDepth = 8
+
+// Options 生成句子的方式
+type Options struct {
+	MaxDepth int                      // MaxDepth 语法树的最大深度 小于等于 0 时为 DefaultMaxDepth
+	Weights  map[rule.Formula]float64 // Weights 产生式的权重 没有给出的为 1 为 0 时只在没有别的选择时使用
+	Coverage bool                     // Coverage 优先选择还没有用过的产生式
+	Seed     int64                    // Seed 随机数种子 相同的种子生成相同的句子
+}
+
+// Generator 随机句子生成器
+type Generator struct {
+	Rules  *rule.Rule
+	Start  string
+	opts   Options
+	rand   *rand.Rand
+	height map[string]int // height 非终结符最矮的语法树的高度
+	used   map[rule.Formula]int
+}
+
+// New 创建生成器 开始符号推导不出终结符串时返回错误
+func New(r *rule.Rule, start string, opts Options) (*Generator, error) {
+	if opts.MaxDepth <= 0 {
+		opts.MaxDepth = DefaultMaxDepth
+	}
+	g := &Generator{
+		Rules:  r,
+		Start:  start,
+		opts:   opts,
+		rand:   rand.New(rand.NewSource(opts.Seed)),
+		height: heights(r),
+		used:   make(map[rule.Formula]int),
+	}
+	if _, ok := g.height[start]; !ok {
+		return nil, errors.New("start symbol " + r.Name(start) + " derives no sentence")
+	}
+	return g, nil
+}
+
+// heights 不动点迭代求每个非终结符最矮的语法树的高度 推导不出终结符串的非终结符不在结果中
+func heights(r *rule.Rule) map[string]int {
+	height := make(map[string]int)
+	for changed := true; changed; {
+		changed = false
+		for _, f := range r.Formulas() {
+			if h, ok := formulaHeight(height, f); ok {
+				if old, seen := height[f.Left]; !seen || h < old {
+					height[f.Left] = h
+					changed = true
+				}
+			}
+		}
+	}
+	return height
+}
+
+// formulaHeight 使用产生式 f 时最矮的语法树的高度
+func formulaHeight(height map[string]int, f *rule.Formula) (int, bool) {
+	h := 1
+	for i := 0; i < len(f.Right); i++ {
+		if util.IsTerminal(f.Right[i]) {
+			continue
+		}
+		sub, ok := height[f.Right[i:i+1]]
+		if !ok {
+			return 0, false
+		}
+		if sub+1 > h {
+			h = sub + 1
+		}
+	}
+	return h, true
+}
+
+// Tree 随机推导出一棵语法树 叶子的词法单元值为终结符的 Lexeme
+func (g *Generator) Tree() *tree.Node {
+	return g.expand(g.Start, 0)
+}
+
+// Sentence 随机推导出一个句子 即语法树的叶子
+func (g *Generator) Sentence() []string {
+	var res []string
+	for _, leaf := range g.Tree().Leaves() {
+		res = append(res, leaf.Symbol)
+	}
+	return res
+}
+
+// expand 在深度 depth 展开 symbol
+// 只选择展开后不会超过最大深度的产生式 没有这样的产生式时选择最矮的
+func (g *Generator) expand(symbol string, depth int) *tree.Node {
+	if util.IsTerminal(symbol[0]) {
+		return tree.Leaf(symbol, &lexer.Token{Value: Lexeme(g.Rules, symbol)})
+	}
+	var candidates []*rule.Formula
+	lowest, shortest := math.MaxInt32, []*rule.Formula(nil)
+	for _, right := range g.Rules.Rules[symbol] {
+		f := &rule.Formula{Left: symbol, Right: right}
+		h, ok := formulaHeight(g.height, f)
+		if !ok {
+			continue
+		}
+		if depth+h <= g.opts.MaxDepth {
+			candidates = append(candidates, f)
+		}
+		if h < lowest {
+			lowest, shortest = h, nil
+		}
+		if h == lowest {
+			shortest = append(shortest, f)
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = shortest
+	}
+	f := g.choose(candidates)
+	g.used[*f]++
+	if f.Right == "&" {
+		return tree.Reduce(symbol, nil)
+	}
+	n := tree.New(symbol)
+	for i := 0; i < len(f.Right); i++ {
+		n.Children = append(n.Children, g.expand(f.Right[i:i+1], depth+1))
+	}
+	return n
+}
+
+// choose 按权重随机选择 打开 Coverage 时先在没有用过的产生式中选择
+func (g *Generator) choose(candidates []*rule.Formula) *rule.Formula {
+	if g.opts.Coverage {
+		var unused []*rule.Formula
+		for _, f := range candidates {
+			if g.used[*f] == 0 {
+				unused = append(unused, f)
+			}
+		}
+		if len(unused) != 0 {
+			candidates = unused
+		}
+	}
+	total := 0.0
+	for _, f := range candidates {
+		total += g.weight(f)
+	}
+	if total == 0 {
+		return candidates[g.rand.Intn(len(candidates))]
+	}
+	x := g.rand.Float64() * total
+	for _, f := range candidates {
+		x -= g.weight(f)
+		if x < 0 {
+			return f
+		}
+	}
+	return candidates[len(candidates)-1]
+}
+
+func (g *Generator) weight(f *rule.Formula) float64 {
+	if w, ok := g.opts.Weights[*f]; ok {
+		return w
+	}
+	return 1
+}
+
+// Unused 还没有用过的产生式 按声明顺序
+func (g *Generator) Unused() []*rule.Formula {
+	var res []*rule.Formula
+	for _, f := range g.Rules.Formulas() {
+		if g.used[*f] == 0 {
+			res = append(res, f)
+		}
+	}
+	return res
+}
+
+// Coverage 用过的产生式个数和全部产生式个数
+func (g *Generator) Coverage() (used int, total int) {
+	total = len(g.Rules.Formulas())
+	return total - len(g.Unused()), total
+}
+
+// typeLexemes 只声明了类型的终结符使用的词法单元
+var typeLexemes = map[lexer.TokenType]string{
+	lexer.VARIABLE: "x",
+	lexer.INTEGER:  "1",
+	lexer.FLOAT:    "1.5",
+	lexer.BOOLEAN:  "true",
+	lexer.STRING:   `"s"`,
+	lexer.TYPE:     "int",
+	lexer.KEYWORD:  "if",
+	lexer.OPERATOR: "+",
+	lexer.BRACKET:  "(",
+}
+
+// Lexeme 终结符对应的源代码 词法分析后能由 rule.Matcher 匹配回这个终结符
+// %token 声明了值的使用这个值 只声明了类型的使用这个类型的例子 其余的就是终结符本身
+func Lexeme(r *rule.Rule, terminal string) string {
+	if p, ok := r.Tokens[terminal]; ok {
+		if p.Value != "" {
+			return p.Value
+		}
+		if len(p.Types) != 0 {
+			return typeLexemes[p.Types[0]]
+		}
+	}
+	return terminal
+}
+
+// Code 句子对应的源代码 词法单元之间用空格分隔
+func Code(r *rule.Rule, sentence []string) string {
+	var res []string
+	for _, terminal := range sentence {
+		res = append(res, Lexeme(r, terminal))
+	}
+	return strings.Join(res, " ")
+}
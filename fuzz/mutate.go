@@ -0,0 +1,84 @@
+package fuzz
+
+import (
+	"github.com/esonhugh/compiler/grammarEarley"
+	"github.com/esonhugh/compiler/grammarLL1/rule"
+)
+
+// 变异的种类
+const (
+	Insert = "insert" // Insert 在 Position 处插入一个终结符
+	Delete = "delete" // Delete 删除 Position 处的终结符
+	Swap   = "swap"   // Swap 交换 Position 和 Position+1 处的终结符
+)
+
+// Mutation 对句子做的一次变异
+type Mutation struct {
+	Kind     string
+	Position int
+	Original []string
+	Sentence []string // Sentence 变异后的句子
+}
+
+// Mutate 对句子随机做一次插入 删除或交换 交换的两个终结符不同 没有可以做的变异时返回 nil
+func (g *Generator) Mutate(sentence []string) *Mutation {
+	terminals := g.Rules.Symbols().Terminals
+	terminals = terminals[:len(terminals)-1]
+	var kinds []string
+	if len(terminals) != 0 {
+		kinds = append(kinds, Insert)
+	}
+	if len(sentence) != 0 {
+		kinds = append(kinds, Delete)
+	}
+	var swaps []int
+	for i := 0; i+1 < len(sentence); i++ {
+		if sentence[i] != sentence[i+1] {
+			swaps = append(swaps, i)
+		}
+	}
+	if len(swaps) != 0 {
+		kinds = append(kinds, Swap)
+	}
+	if len(kinds) == 0 {
+		return nil
+	}
+	m := &Mutation{Kind: kinds[g.rand.Intn(len(kinds))], Original: sentence}
+	res := append([]string(nil), sentence...)
+	switch m.Kind {
+	case Insert:
+		m.Position = g.rand.Intn(len(sentence) + 1)
+		t := terminals[g.rand.Intn(len(terminals))]
+		res = append(res[:m.Position], append([]string{t}, res[m.Position:]...)...)
+	case Delete:
+		m.Position = g.rand.Intn(len(sentence))
+		res = append(res[:m.Position], res[m.Position+1:]...)
+	case Swap:
+		m.Position = swaps[g.rand.Intn(len(swaps))]
+		res[m.Position], res[m.Position+1] = res[m.Position+1], res[m.Position]
+	}
+	m.Sentence = res
+	return m
+}
+
+// Invalid 生成一个句子并变异 直到得到文法不接受的句子 最多尝试 tries 次
+// 是否接受由 Earley 分析器判断 因此对任何文法都适用
+func (g *Generator) Invalid(tries int) (*Mutation, bool) {
+	p := grammarEarley.NewParser(g.Rules, g.Start)
+	for i := 0; i < tries; i++ {
+		m := g.Mutate(g.Sentence())
+		if m == nil {
+			continue
+		}
+		if _, err := p.ParseTerminals(m.Sentence); err != nil {
+			return m, true
+		}
+	}
+	return nil, false
+}
+
+// Accepts 文法是否接受句子
+func Accepts(r *rule.Rule, start string, sentence []string) bool {
+	_, err := grammarEarley.NewParser(r, start).ParseTerminals(sentence)
+	return err == nil
+}
@@ -11,3 +11,7 @@ type Production struct {
 	Origin string
 	Next   string
 }
+
+// Rules 递归下降分析器使用的文法 与 makeProductions 相同
+// 按 rule.Rule.AddRules 的格式书写 供其他分析器对照 G 和 S 分别显示为 E' 和 T'
+const Rules = "%name G E'\n%name S T'\nE->TG\nG->ATG|&\nT->FS\nS->MFS|&\nF->(E)|i\nA->+|-\nM->*|/"
@@ -2,6 +2,7 @@ package grammar
 
 import (
 	"bytes"
+	"github.com/esonhugh/compiler/grammarLL1/rule"
 	"github.com/esonhugh/compiler/lexer"
 	"github.com/esonhugh/compiler/util"
 	"fmt"
@@ -12,12 +13,19 @@ import (
 // EndToken 句子终结符
 const EndToken = "$"
 
+// matcher 词法单元与终结符的对应关系 与其他分析器相同 i 匹配变量和常量
+var matcher = rule.NewMatcher([]string{"i", "+", "-", "*", "/", "(", ")", ";"}, nil)
+
 // Grammar 对象定义了一个语法分析需要的全部对象 以及他的分析器
 type Grammar struct {
 	*util.Stream                     // Stream is the stream of the sentence
 	productions  map[string][]string // productions is the production map use the "string"=>"string1" "string"=>"string2" ...
 	endToken     string              // endToken is the end of the sentence
 	tokens       *util.Queue         // tokens is the queue of the sentence
+
+	index    map[*lexer.Token]int // index 词法单元在输入中的位置
+	furthest int                  // furthest 分析器走到的最远位置
+	expected map[string]struct{}  // expected 最远位置上期望的终结符
 }
 
 /* makeProductions 创建产生式的映射关系 map
@@ -51,23 +59,55 @@ func makeProductions() map[string][]string {
 	return res
 }
 
-// Analyse 语法分析器主函数
-func Analyse(raw []*lexer.Token) ([]*Production, bool) {
-	// 从 E 开始 E 即为开始符号 到 EndToken 为句子结束符号
-	grm := NewGrammar(raw, bytes.NewBufferString("E"), EndToken)
-	productions, success := grm.Analyse(1)
-	if !success {
-		return nil, false
+// Analyse 语法分析器主函数 程序由若干条以 ; 结尾的表达式语句组成 最后一条语句可以不写 ;
+// 一条语句出错时记录分析器走到的最远位置和那里期望的终结符 然后跳到同步符号 ; 之后继续分析下一条语句
+// 返回正确的语句的最左推导和全部错误
+func Analyse(raw []*lexer.Token) ([]*Production, Diagnostics) {
+	var tokens []*lexer.Token
+	for _, t := range raw {
+		if t.Typ != lexer.COMMENT {
+			tokens = append(tokens, t)
+		}
 	}
-	e := grm.tokens.FrontRaw()
-	if e != nil {
-		if e.Value.(*lexer.Token).Value == "" && e.Next() == nil {
-			return productions, true
+	var res []*Production
+	var diagnostics Diagnostics
+	for pos := 0; pos < len(tokens); {
+		// 从 E 开始 E 即为开始符号 到 EndToken 为句子结束符号
+		grm := NewGrammar(tokens[pos:], bytes.NewBufferString("E"), EndToken)
+		productions, success := grm.Analyse(1)
+		if success {
+			next := grm.position()
+			if pos+next == len(tokens) {
+				return append(res, productions...), diagnostics
+			}
+			if matcher.Match(";", tokens[pos+next]) {
+				res = append(res, productions...)
+				pos += next + 1
+				continue
+			}
+			grm.fail(";")
 		}
-		return nil, false
+		diagnostics = append(diagnostics, diagnostic(tokens, pos+grm.furthest, grm.expected))
+		pos = synchronize(tokens, pos+grm.furthest)
 	}
+	return res, diagnostics
+}
 
-	return productions, true
+// AnalyseSentence 只分析一条表达式 输入必须恰好是开始符号 E 推导出的句子 不接受 ; 分隔的语句序列
+// 出错时返回分析器走到的最远位置上的错误
+func AnalyseSentence(raw []*lexer.Token) ([]*Production, *Diagnostic) {
+	var tokens []*lexer.Token
+	for _, t := range raw {
+		if t.Typ != lexer.COMMENT {
+			tokens = append(tokens, t)
+		}
+	}
+	grm := NewGrammar(tokens, bytes.NewBufferString("E"), EndToken)
+	productions, success := grm.Analyse(1)
+	if success && grm.position() == len(tokens) {
+		return productions, nil
+	}
+	return nil, diagnostic(tokens, grm.furthest, grm.expected)
 }
 
 func NewGrammar(token []*lexer.Token, r io.Reader, et string) *Grammar {
@@ -76,7 +116,11 @@ func NewGrammar(token []*lexer.Token, r io.Reader, et string) *Grammar {
 	for i := 0; i < len(token); i++ {
 		q.PushBack(token[i])
 	}
-	return &Grammar{Stream: s, endToken: et, productions: makeProductions(), tokens: q}
+	index := make(map[*lexer.Token]int)
+	for i, t := range token {
+		index[t] = i
+	}
+	return &Grammar{Stream: s, endToken: et, productions: makeProductions(), tokens: q, index: index, expected: make(map[string]struct{})}
 }
 
 
@@ -127,101 +171,32 @@ func (g *Grammar) Analyse(count int) (res []*Production, canKill bool) {
 	if g.isEndType(origin) {
 		c := g.tokens.Front()
 		if c == nil {
+			g.fail(origin)
 			return res, false
 		}
 		currentToken := c.(*lexer.Token)
-		switch origin {
-		case "i":
-			{
-				if !currentToken.IsValue() {
-					g.Stream.ClearFronts(count - 1)
-					return res, false
-				}
-				res = append(res, &Production{
-					Type:   "kill",
-					Target: "i",
-				})
-				return res, true
-			}
-		case "+":
-			{
-				if !currentToken.IsOperator() || currentToken.Value != "+" {
-					g.Stream.ClearFronts(count - 1)
-					return res, false
-				}
-				res = append(res, &Production{
-					Type:   "kill",
-					Target: "+",
-				})
-				return res, true
-			}
-		case "-":
-			{
-				if !currentToken.IsOperator() || currentToken.Value != "-" {
-					g.Stream.ClearFronts(count - 1)
-					return res, false
-				}
-				res = append(res, &Production{
-					Type:   "kill",
-					Target: "-",
-				})
-				return res, true
-			}
-		case "*":
-			{
-				if !currentToken.IsOperator() || currentToken.Value != "*" {
-					g.Stream.ClearFronts(count - 1)
-					return res, false
-				}
-				res = append(res, &Production{
-					Type:   "kill",
-					Target: "*",
-				})
-				return res, true
-			}
-		case "/":
-			{
-				if !currentToken.IsOperator() || currentToken.Value != "/" {
-					g.Stream.ClearFronts(count - 1)
-					return res, false
-				}
-				res = append(res, &Production{
-					Type:   "kill",
-					Target: "/",
-				})
-				return res, true
-			}
-		case "(":
-			{
-				if !currentToken.IsBracket() || currentToken.Value != "(" {
-					g.Stream.ClearFronts(count - 1)
-					return res, false
-				}
-				res = append(res, &Production{
-					Type:   "kill",
-					Target: "(",
-				})
-				return res, true
-			}
-		case ")":
-			{
-				if !currentToken.IsBracket() || currentToken.Value != ")" {
-					g.Stream.ClearFronts(count - 1)
-					return res, false
-				}
-				res = append(res, &Production{
-					Type:   "kill",
-					Target: ")",
-				})
-				return res, true
-			}
+		if !matcher.Match(origin, currentToken) {
+			g.fail(origin)
+			g.Stream.ClearFronts(count - 1)
+			return res, false
 		}
+		res = append(res, &Production{
+			Type:   "kill",
+			Target: origin,
+		})
+		return res, true
 	}
 	
 	// 选择一个可用的产生式子
 	canUse := g.productions[origin]
-	var killToken []*lexer.Token
 	for i := 0; i < len(canUse); i++ {
+		// 这个产生式的推导过程和已经分析过的词法单元 产生式失败时丢弃推导过程 放回词法单元
+		var killToken []*lexer.Token
+		alternative := []*Production{{
+			Origin: origin,
+			Next:   canUse[i],
+			Type:   "Continue",
+		}}
 		// 倒序插入
 		for _, s := range reverse(strings.Split(canUse[i], "")) {
 			g.Stream.PutBack(s)
@@ -236,12 +211,7 @@ func (g *Grammar) Analyse(count int) (res []*Production, canKill bool) {
 			next := len(canUse[i]) - j
 			ps, kill := g.Analyse(next)
 			if kill {
-				res = append(res, &Production{
-					Origin: origin,
-					Next:   canUse[i],
-					Type:   "Continue",
-				})
-				res = append(res, ps...)
+				alternative = append(alternative, ps...)
 				if j != len(canUse[i])-1 {
 					p := g.tokens.Pop()
 					if p != nil {
@@ -264,10 +234,7 @@ func (g *Grammar) Analyse(count int) (res []*Production, canKill bool) {
 			break
 		}
 		if match {
-			if canUse[i] == "" {
-				return res, true
-			}
-			return res, true
+			return append(res, alternative...), true
 		}
 
 	}
@@ -276,6 +243,27 @@ func (g *Grammar) Analyse(count int) (res []*Production, canKill bool) {
 	return res, false
 }
 
+// fail 当前位置期望终结符 terminal 只保留最远位置上的期望
+func (g *Grammar) fail(terminal string) {
+	pos := g.position()
+	if pos > g.furthest {
+		g.furthest, g.expected = pos, make(map[string]struct{})
+	}
+	if pos == g.furthest {
+		g.expected[terminal] = struct{}{}
+	}
+}
+
+// position 第一个还没有分析的词法单元的位置 跳过推导出空串时放入的空词法单元
+func (g *Grammar) position() int {
+	for e := g.tokens.FrontRaw(); e != nil; e = e.Next() {
+		if i, ok := g.index[e.Value.(*lexer.Token)]; ok {
+			return i
+		}
+	}
+	return len(g.index)
+}
+
 // reverse 反转字符串
 func reverse(s []string) []string {
 	for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
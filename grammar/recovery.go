@@ -0,0 +1,79 @@
+package grammar
+
+import (
+	"fmt"
+	"github.com/esonhugh/compiler/lexer"
+	"sort"
+	"strings"
+)
+
+// Diagnostic 一个语法错误 位置为分析器走到的最远的词法单元
+type Diagnostic struct {
+	Row      int
+	Column   int
+	Expected []string
+	Found    *lexer.Token
+}
+
+// String 输出错误 line 3 col 7: expected ')' or operator, found 'i'
+func (d *Diagnostic) String() string {
+	found := "end of input"
+	if d.Found.Typ != lexer.END {
+		found = "'" + d.Found.Value + "'"
+	}
+	return fmt.Sprintf("line %d col %d: expected %s, found %s", d.Row, d.Column, strings.Join(d.Expected, " or "), found)
+}
+
+// Diagnostics 全部错误
+type Diagnostics []*Diagnostic
+
+// String 每行一个错误
+func (ds Diagnostics) String() string {
+	var build strings.Builder
+	for _, d := range ds {
+		build.WriteString(d.String() + "\n")
+	}
+	return build.String()
+}
+
+// diagnostic 第 pos 个词法单元上的错误 pos 为 len(tokens) 时位置为最后一个词法单元之后
+func diagnostic(tokens []*lexer.Token, pos int, expected map[string]struct{}) *Diagnostic {
+	found := &lexer.Token{Typ: lexer.END, Value: EndToken, Row: 1, Column: 1}
+	if pos < len(tokens) {
+		found = tokens[pos]
+	} else if len(tokens) > 0 {
+		last := tokens[len(tokens)-1]
+		found.Row, found.Column = last.Row, last.Column+len(last.Value)
+	}
+	described := make(map[string]struct{})
+	for terminal := range expected {
+		described[describe(terminal)] = struct{}{}
+	}
+	var res []string
+	for what := range described {
+		res = append(res, what)
+	}
+	sort.Strings(res)
+	return &Diagnostic{Row: found.Row, Column: found.Column, Expected: res, Found: found}
+}
+
+// describe 错误信息中终结符的写法 四则运算符号合称 operator
+func describe(terminal string) string {
+	switch terminal {
+	case "+", "-", "*", "/":
+		return "operator"
+	case "i":
+		return "identifier"
+	}
+	return "'" + terminal + "'"
+}
+
+// synchronize 从第 pos 个词法单元跳到下一个 ; 之后 返回继续分析的位置
+func synchronize(tokens []*lexer.Token, pos int) int {
+	for ; pos < len(tokens); pos++ {
+		if matcher.Match(";", tokens[pos]) {
+			return pos + 1
+		}
+	}
+	return pos
+}
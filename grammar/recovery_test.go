@@ -0,0 +1,47 @@
+package grammar
+
+import (
+	"github.com/esonhugh/compiler/lexer"
+	"strings"
+	"testing"
+)
+
+func TestAnalyse(t *testing.T) {
+	prod, diagnostics := Analyse(lexer.Analyse("a * b;\nc"))
+	if len(diagnostics) != 0 {
+		t.Fatalf("unexpected errors\n%s", diagnostics.String())
+	}
+	var steps []string
+	for _, p := range prod {
+		if p.Type == "kill" {
+			steps = append(steps, p.Target)
+		} else {
+			steps = append(steps, p.Origin+"->"+p.Next)
+		}
+	}
+	want := "E->TG T->FS F->i i S->MFS M->* * F->i i S->& & G->& & " +
+		"E->TG T->FS F->i i S->& & G->& &"
+	if got := strings.Join(steps, " "); got != want {
+		t.Fatalf("got %s\nwant %s", got, want)
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	code := "a + b;\n(a + b c;\nd * ;\ne - f\ng"
+	_, diagnostics := Analyse(lexer.Analyse(code))
+	want := []string{
+		"line 2 col 8: expected ')' or operator, found 'c'",
+		"line 3 col 5: expected '(' or identifier, found ';'",
+		"line 5 col 1: expected ';' or operator, found 'g'",
+	}
+	if got := strings.TrimSpace(diagnostics.String()); got != strings.Join(want, "\n") {
+		t.Fatalf("got\n%s\nwant\n%s", got, strings.Join(want, "\n"))
+	}
+}
+
+func TestDiagnosticEnd(t *testing.T) {
+	_, diagnostics := Analyse(lexer.Analyse("(a + b"))
+	if len(diagnostics) != 1 || diagnostics[0].String() != "line 1 col 7: expected ')' or operator, found end of input" {
+		t.Fatalf("unexpected errors\n%s", diagnostics.String())
+	}
+}
@@ -0,0 +1,169 @@
+/*
+Package grammarEarley Earley 语法分析器包 可以分析任意上下文无关文法
+
+左递归 二义文法和空产生式都可以处理 空产生式使用 Aycock–Horspool 的方法
+预测一个可空的非终结符时 直接把圆点移过它
+分析结果是一个共享压缩语法森林 (SPPF) 可以从中取出全部或者前 N 棵语法树
+*/
+package grammarEarley
+
+import (
+	"errors"
+	"github.com/esonhugh/compiler/grammarLL1/first"
+	"github.com/esonhugh/compiler/grammarLL1/rule"
+	"github.com/esonhugh/compiler/grammarLL1/util"
+	"github.com/esonhugh/compiler/lexer"
+	"github.com/esonhugh/compiler/tree"
+)
+
+// Item Earley 项目 A->α·β 以及它开始的位置
+type Item struct {
+	Formula int
+	Dot     int
+	Origin  int
+}
+
+// Parser Earley 分析器
+type Parser struct {
+	Rules    *rule.Rule
+	Start    string
+	formulas []*rule.Formula
+	nullable first.Nullable
+	matcher  *rule.Matcher
+	tokens   []*lexer.Token
+	input    []string // input 每个词法单元对应的终结符
+	Chart    [][]Item // Chart 每个位置的项目集
+	seen     []map[Item]bool
+}
+
+// Analyze 读入规则并分析
+func Analyze(raw []*lexer.Token, rules string, start string) (*tree.Forest, bool) {
+	r := rule.NewRules()
+	if err := r.AddRules(rules); err != nil {
+		return nil, false
+	}
+	forest, err := NewParser(r, start).Parse(raw)
+	return forest, err == nil
+}
+
+// NewParser 创建一个新的 Earley 分析器
+func NewParser(r *rule.Rule, start string) *Parser {
+	return &Parser{Rules: r, Start: start, formulas: r.Formulas(), nullable: first.GetNullableSet(r), matcher: r.Matcher()}
+}
+
+// Parse 分析词法单元序列 成功时返回语法森林
+func (p *Parser) Parse(tokens []*lexer.Token) (*tree.Forest, error) {
+	var input []string
+	for _, t := range tokens {
+		input = append(input, p.matcher.TerminalOf(t))
+	}
+	return p.parse(tokens, input)
+}
+
+// ParseTerminals 直接分析终结符序列 不经过词法单元的匹配 叶子结点的词法单元值为终结符本身
+func (p *Parser) ParseTerminals(terminals []string) (*tree.Forest, error) {
+	var tokens []*lexer.Token
+	for _, t := range terminals {
+		tokens = append(tokens, &lexer.Token{Value: t})
+	}
+	return p.parse(tokens, terminals)
+}
+
+func (p *Parser) parse(tokens []*lexer.Token, input []string) (*tree.Forest, error) {
+	p.tokens = tokens
+	p.input = input
+	n := len(tokens)
+	p.Chart = make([][]Item, n+1)
+	p.seen = make([]map[Item]bool, n+1)
+	for i := range p.seen {
+		p.seen[i] = make(map[Item]bool)
+	}
+	for f, formula := range p.formulas {
+		if formula.Left == p.Start {
+			p.add(0, Item{Formula: f})
+		}
+	}
+	for j := 0; j <= n; j++ {
+		for k := 0; k < len(p.Chart[j]); k++ {
+			i := p.Chart[j][k]
+			next := p.next(i)
+			switch {
+			case next == "":
+				p.complete(j, i)
+			case util.IsTerminal(next[0]):
+				if j < n && p.input[j] == next {
+					p.add(j+1, Item{Formula: i.Formula, Dot: i.Dot + 1, Origin: i.Origin})
+				}
+			default:
+				p.predict(j, i, next)
+			}
+		}
+	}
+	for _, i := range p.Chart[n] {
+		if i.Origin == 0 && p.formulas[i.Formula].Left == p.Start && p.next(i) == "" {
+			return newForest(p), nil
+		}
+	}
+	return nil, errors.New("earley: input rejected at " + p.position())
+}
+
+// predict 预测 B 的全部产生式 B 可空时圆点直接移过 B
+func (p *Parser) predict(j int, i Item, b string) {
+	for f, formula := range p.formulas {
+		if formula.Left == b {
+			p.add(j, Item{Formula: f, Origin: j})
+		}
+	}
+	if p.nullable[b] {
+		p.add(j, Item{Formula: i.Formula, Dot: i.Dot + 1, Origin: i.Origin})
+	}
+}
+
+// complete 完成项目 A->γ· 推进所有等待 A 的项目
+func (p *Parser) complete(j int, i Item) {
+	left := p.formulas[i.Formula].Left
+	for k := 0; k < len(p.Chart[i.Origin]); k++ {
+		w := p.Chart[i.Origin][k]
+		if p.next(w) == left {
+			p.add(j, Item{Formula: w.Formula, Dot: w.Dot + 1, Origin: w.Origin})
+		}
+	}
+}
+
+func (p *Parser) add(j int, i Item) {
+	if !p.seen[j][i] {
+		p.seen[j][i] = true
+		p.Chart[j] = append(p.Chart[j], i)
+	}
+}
+
+// right 产生式右部 空产生式为空串
+func (p *Parser) right(f int) string {
+	if p.formulas[f].Right == "&" {
+		return ""
+	}
+	return p.formulas[f].Right
+}
+
+// next 圆点后面的符号
+func (p *Parser) next(i Item) string {
+	right := p.right(i.Formula)
+	if i.Dot >= len(right) {
+		return ""
+	}
+	return string(right[i.Dot])
+}
+
+// position 最远能分析到的位置
+func (p *Parser) position() string {
+	last := 0
+	for j := range p.Chart {
+		if len(p.Chart[j]) != 0 {
+			last = j
+		}
+	}
+	if last < len(p.tokens) {
+		return "token " + p.tokens[last].Value
+	}
+	return "end of input"
+}
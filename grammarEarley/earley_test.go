@@ -0,0 +1,78 @@
+package grammarEarley
+
+import (
+	"github.com/esonhugh/compiler/grammarLL1/rule"
+	"github.com/esonhugh/compiler/grammarLR"
+	"github.com/esonhugh/compiler/lexer"
+	"github.com/esonhugh/compiler/tree"
+	"testing"
+)
+
+func parse(t *testing.T, rules, start, code string) (*tree.Forest, error) {
+	return NewParser(rule.MustParse(rules), start).Parse(lexer.Analyse(code))
+}
+
+func TestLeftRecursion(t *testing.T) {
+	forest, err := parse(t, "E->E+T|T\nT->T*F|F\nF->(E)|i", "E", "a+b*c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	trees := forest.Trees(0)
+	if len(trees) != 1 || forest.IsAmbiguous() {
+		t.Fatalf("expect exactly one tree, got %d", len(trees))
+	}
+	// 与 LR 分析器的结果一致
+	table, _ := grammarLR.SLRTable("E->E+T|T\nT->T*F|F\nF->(E)|i", "E")
+	g := grammarLR.NewGrammar(lexer.Analyse("a+b*c"), table)
+	if _, ok := g.Analyze(); !ok || !g.Tree.Equal(trees[0]) {
+		t.Fatalf("earley %s differs from LR %s", trees[0].String(), g.Tree.String())
+	}
+	if _, err := parse(t, "E->E+T|T\nT->T*F|F\nF->(E)|i", "E", "a+*c"); err == nil {
+		t.Fatal("a+*c should be rejected")
+	}
+}
+
+func TestAmbiguity(t *testing.T) {
+	// 卡特兰数 i+i+i 有 2 棵树 i+i+i+i 有 5 棵树
+	for code, want := range map[string]int{"a": 1, "a+b": 1, "a+b+c": 2, "a+b+c+d": 5} {
+		forest, err := parse(t, "E->E+E|i", "E", code)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := len(forest.Trees(0)); got != want {
+			t.Errorf("%s: got %d trees, want %d", code, got, want)
+		}
+	}
+	forest, _ := parse(t, "E->E+E|i", "E", "a+b+c+d")
+	t.Log(forest.String())
+	if len(forest.Trees(3)) != 3 {
+		t.Error("limit should be respected")
+	}
+}
+
+func TestEmptyProductions(t *testing.T) {
+	// 需要 Aycock–Horspool 的处理 否则 A 可空时 P->AAb 无法完成
+	rules := "P->AAb|AB\nA->&|a\nB->AA"
+	for _, code := range []string{"", "b", "a b", "a a b", "a", "a a"} {
+		if _, err := parse(t, rules, "P", code); err != nil {
+			t.Errorf("%q should be accepted: %v", code, err)
+		}
+	}
+	if _, err := parse(t, rules, "P", "a a a a"); err == nil {
+		t.Error("a a a a should be rejected")
+	}
+	forest, _ := parse(t, rules, "P", "a")
+	for _, tr := range forest.Trees(0) {
+		t.Log(tr.String())
+	}
+}
+
+func TestCycle(t *testing.T) {
+	forest, err := parse(t, "P->P|a", "P", "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trees := forest.Trees(0); len(trees) != 1 || trees[0].String() != "P(a)" {
+		t.Fatalf("unexpected trees %v", trees)
+	}
+}
@@ -0,0 +1,87 @@
+package grammarEarley
+
+import (
+	"github.com/esonhugh/compiler/grammarLL1/util"
+	"github.com/esonhugh/compiler/tree"
+)
+
+// forestBuilder 根据识别完成的项目集构造共享压缩语法森林
+type forestBuilder struct {
+	parser *Parser
+	forest *tree.Forest
+	spans  map[span]bool
+}
+
+// span 已经识别出的 Symbol 推导第 start 到 end 个词法单元
+type span struct {
+	symbol     string
+	start, end int
+}
+
+// newForest 根据识别完成的项目集构造森林
+func newForest(p *Parser) *tree.Forest {
+	b := &forestBuilder{parser: p, forest: tree.NewForest(), spans: make(map[span]bool)}
+	for j, items := range p.Chart {
+		for _, i := range items {
+			if p.next(i) == "" {
+				b.spans[span{p.formulas[i.Formula].Left, i.Origin, j}] = true
+			}
+		}
+	}
+	b.forest.Root = b.node(p.Start, 0, len(p.tokens))
+	return b.forest
+}
+
+// node 取得或者创建符号结点 先登记再展开 所以环形的推导也能表示
+func (b *forestBuilder) node(symbol string, start, end int) *tree.ForestNode {
+	n, created := b.forest.Node(symbol, start, end)
+	if !created {
+		return n
+	}
+	p := b.parser
+	if util.IsTerminal(symbol[0]) {
+		n.Token = p.tokens[start]
+		return n
+	}
+	for _, i := range p.Chart[end] {
+		if i.Origin != start || p.next(i) != "" || p.formulas[i.Formula].Left != symbol {
+			continue
+		}
+		for _, children := range b.decompose(i.Formula, i.Dot, start, end) {
+			b.forest.Pack(n, p.formulas[i.Formula], children)
+		}
+	}
+	return n
+}
+
+// decompose 产生式前 dot 个符号推导出 origin 到 pos 之间词法单元的全部切分方式
+func (b *forestBuilder) decompose(formula, dot, origin, pos int) [][]*tree.ForestNode {
+	if dot == 0 {
+		if pos == origin {
+			return [][]*tree.ForestNode{{}}
+		}
+		return nil
+	}
+	p := b.parser
+	x := string(p.right(formula)[dot-1])
+	prev := Item{Formula: formula, Dot: dot - 1, Origin: origin}
+	var res [][]*tree.ForestNode
+	if util.IsTerminal(x[0]) {
+		if pos-1 < origin || p.input[pos-1] != x || !p.seen[pos-1][prev] {
+			return nil
+		}
+		for _, children := range b.decompose(formula, dot-1, origin, pos-1) {
+			res = append(res, append(append([]*tree.ForestNode(nil), children...), b.node(x, pos-1, pos)))
+		}
+		return res
+	}
+	for q := origin; q <= pos; q++ {
+		if !p.seen[q][prev] || !b.spans[span{x, q, pos}] {
+			continue
+		}
+		for _, children := range b.decompose(formula, dot-1, origin, q) {
+			res = append(res, append(append([]*tree.ForestNode(nil), children...), b.node(x, q, pos)))
+		}
+	}
+	return res
+}
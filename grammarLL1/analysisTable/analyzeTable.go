@@ -8,22 +8,35 @@ import (
 	"github.com/esonhugh/compiler/grammarLL1/first"
 	"github.com/esonhugh/compiler/grammarLL1/follow"
 	"github.com/esonhugh/compiler/grammarLL1/rule"
-	"github.com/esonhugh/compiler/util/transfer"
 	"fmt"
 	"github.com/liushuochen/gotable"
+	"sort"
 )
 
-// SymbolTable 即为分析表 是一个二维度结构的 map
-type SymbolTable map[string]map[string]*rule.Formula
+// SymbolTable 即为分析表 Cells[A][a] 为非终结符 A 遇到终结符 a 时使用的产生式
+// 行按非终结符的声明顺序 列按终结符的字典序 # 在最后
+type SymbolTable struct {
+	Symbols      *rule.Symbols
+	Nonterminals []string
+	Terminals    []string
+	Cells        map[string]map[string]*rule.Formula
+}
 
 // GetAnalysisTable 获取根据 first 集 follow 集 构建分析表
+// 出现冲突时保留先声明的产生式 需要冲突信息时使用 BuildAnalyzeTable
 func GetAnalyzeTable(firstSet first.FirstSet, followSet follow.FollowSet, rules *rule.Rule) SymbolTable {
-	symbolTable := make(SymbolTable)
+	symbolTable, _ := BuildAnalyzeTable(firstSet, followSet, rules, "")
+	return symbolTable
+}
+
+// BuildAnalyzeTable 构建分析表 同时收集所有的 FIRST/FIRST 和 FIRST/FOLLOW 冲突
+// start 为开始符号 用于给 FIRST/FOLLOW 冲突构造推导过程
+func BuildAnalyzeTable(firstSet first.FirstSet, followSet follow.FollowSet, rules *rule.Rule, start string) (SymbolTable, Conflicts) {
+	symbolTable := SymbolTable{Symbols: firstSet.Symbols, Cells: make(map[string]map[string]*rule.Formula)}
+	rows := make(map[string]struct{})
 	endSymbol := make(map[string]struct{})
-	for key, firstSets := range firstSet {
-		if _, ok := symbolTable[key]; !ok {
-			symbolTable[key] = make(map[string]*rule.Formula)
-		}
+	for key, firstSets := range firstSet.Sets {
+		rows[key] = struct{}{}
 		for set := range firstSets {
 			if set == "&" {
 				continue
@@ -31,92 +44,97 @@ func GetAnalyzeTable(firstSet first.FirstSet, followSet follow.FollowSet, rules
 			endSymbol[set] = struct{}{}
 		}
 	}
-	for _, followSets := range followSet {
+	for _, followSets := range followSet.Sets {
 		for set := range followSets {
 			endSymbol[set] = struct{}{}
 		}
 	}
+	symbolTable.Nonterminals = firstSet.Symbols.Sort(rows)
+	symbolTable.Terminals = firstSet.Symbols.Sort(endSymbol)
 
-	for left := range firstSet {
-		for key, _ := range endSymbol {
-			symbolTable[left][key] = nil
+	for _, left := range symbolTable.Nonterminals {
+		symbolTable.Cells[left] = make(map[string]*rule.Formula)
+		for _, key := range symbolTable.Terminals {
+			symbolTable.Cells[left][key] = nil
 		}
 	}
 	// 到此表结构组装初始化完成
 
-	changed := false
-	// 判断是否有新的变化 如果有就继续更迭 直到不再加入表的新变化
-	for {
-		changed = false
-		for left, sets := range firstSet {
-			for set, _ := range sets {
-				if symbolTable[left][set] != nil {
-					continue
-				}
-
-				// first集有空集
-				if set == "&" {
-					for fl := range followSet[left] {
-						if symbolTable[left][fl] == nil {
-							symbolTable[left][fl] = &rule.Formula{
-								Left:  left,
-								Right: set,
-							}
-							changed = true
-
-						}
-					}
-					continue
-				}
-
-				// 无空集
-				symbolTable[left][set] = rules.GetProcessMethod(left, set)
-				if symbolTable[left][set] != nil {
-					changed = true
-				}
-				
-				// 没匹配到
-				if symbolTable[left][set] == nil {
-					for _, out := range rules.Rules[left] {
-						if firstSet.IsInFirstSet(string(out[0]), set) {
-							symbolTable[left][set] = &rule.Formula{
-								Left:  left,
-								Right: out,
-							}
-							changed = true
-						}
-					}
+	// 每个格子里所有能预测到的产生式 以及预测的原因
+	cells := make(map[string]map[string][]*prediction)
+	var order []cellKey
+	predict := func(left, set string, formula *rule.Formula, byFollow bool) {
+		if cells[left] == nil {
+			cells[left] = make(map[string][]*prediction)
+		}
+		if len(cells[left][set]) == 0 {
+			order = append(order, cellKey{left, set})
+		}
+		for _, p := range cells[left][set] {
+			if p.formula.Right == formula.Right {
+				return
+			}
+		}
+		cells[left][set] = append(cells[left][set], &prediction{formula: formula, byFollow: byFollow})
+	}
+	for _, formula := range rules.Formulas() {
+		if _, ok := symbolTable.Cells[formula.Left]; !ok {
+			continue
+		}
+		sets := firstSet.FirstOf(formula.Right)
+		for _, set := range firstSet.Symbols.Sort(sets) {
+			if set == "&" {
+				// first集有空集 按 FOLLOW 集填表
+				for _, fl := range followSet.Elements(formula.Left) {
+					predict(formula.Left, fl, formula, true)
 				}
+				continue
 			}
+			predict(formula.Left, set, formula, false)
 		}
-		if !changed {
-			break
+	}
+
+	sort.SliceStable(order, func(i, j int) bool {
+		if order[i].left != order[j].left {
+			return firstSet.Symbols.Less(order[i].left, order[j].left)
+		}
+		return firstSet.Symbols.Less(order[i].set, order[j].set)
+	})
+	var conflicts Conflicts
+	for _, key := range order {
+		predictions := cells[key.left][key.set]
+		// 先声明的产生式优先
+		symbolTable.Cells[key.left][key.set] = predictions[0].formula
+		if len(predictions) > 1 {
+			conflicts = append(conflicts, newConflict(firstSet, rules, start, key.left, key.set, predictions))
 		}
 	}
-	return symbolTable
+	return symbolTable, conflicts
 }
 
+// Get 非终结符 left 遇到终结符 terminal 时使用的产生式 出错时为 nil
+func (s SymbolTable) Get(left, terminal string) *rule.Formula {
+	return s.Cells[left][terminal]
+}
+
+// String 按 Nonterminals 和 Terminals 的顺序输出分析表
 func (s SymbolTable) String() string {
 	column := []string{" "}
-	for rowKey := range s {
-		for colKey := range s[rowKey] {
-			column = append(column, colKey)
-		}
-		break
+	for _, col := range s.Terminals {
+		column = append(column, s.Symbols.Name(col))
 	}
 	table, err := gotable.Create(column...)
 	if err != nil {
 		fmt.Println(err.Error())
 		return ""
 	}
-	for rowKey := range s {
+	for _, rowKey := range s.Nonterminals {
 		row := make(map[string]string)
-		row[" "] = rowKey
-		for col, formula := range s[rowKey] {
-			if formula == nil {
-				row[col] = ""
-			} else {
-				row[col] = fmt.Sprintf("%s->%s", transfer.Transfer(formula.Left), transfer.Transfer(formula.Right))
+		row[" "] = s.Symbols.Name(rowKey)
+		for _, col := range s.Terminals {
+			row[s.Symbols.Name(col)] = ""
+			if formula := s.Cells[rowKey][col]; formula != nil {
+				row[s.Symbols.Name(col)] = fmt.Sprintf("%s->%s", s.Symbols.Name(formula.Left), s.Symbols.Name(formula.Right))
 			}
 		}
 		err = table.AddRow(row)
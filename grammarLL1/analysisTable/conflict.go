@@ -0,0 +1,235 @@
+package analysisTable
+
+import (
+	"fmt"
+	"github.com/esonhugh/compiler/grammarLL1/first"
+	"github.com/esonhugh/compiler/grammarLL1/rule"
+	"github.com/esonhugh/compiler/grammarLL1/util"
+	"github.com/esonhugh/compiler/util/transfer"
+	"strings"
+)
+
+// 冲突类型
+const (
+	FirstFirst  = "FIRST/FIRST"
+	FirstFollow = "FIRST/FOLLOW"
+)
+
+// maxSearch 构造推导过程时最多搜索的句型个数
+const maxSearch = 2000
+
+// Conflict 分析表中一个格子被多个产生式竞争
+type Conflict struct {
+	Kind        string            // Kind FIRST/FIRST 或 FIRST/FOLLOW
+	Left        string            // Left 非终结符
+	Lookahead   string            // Lookahead 向前看的终结符
+	Formulas    []*rule.Formula   // Formulas 竞争的产生式
+	Derivations []string          // Derivations 每个产生式为什么能预测到 Lookahead
+	names       map[string]string // names 文法的显示名
+}
+
+// Conflicts 分析表的全部冲突
+type Conflicts []*Conflict
+
+// IsLL1 没有任何冲突时文法是 LL(1) 的
+func (c Conflicts) IsLL1() bool {
+	return len(c) == 0
+}
+
+// String 输出冲突报告
+func (c Conflicts) String() string {
+	if c.IsLL1() {
+		return "LL(1) grammar, no conflict\n"
+	}
+	var build strings.Builder
+	for _, conflict := range c {
+		build.WriteString(conflict.String())
+	}
+	return build.String()
+}
+
+// String 输出一个冲突 以及每个产生式的推导过程
+func (c *Conflict) String() string {
+	var build strings.Builder
+	build.WriteString(fmt.Sprintf("%s conflict at M[%s, %s]:\n", c.Kind, transfer.TransferWith(c.Left, c.names), transfer.TransferWith(c.Lookahead, c.names)))
+	for i, formula := range c.Formulas {
+		build.WriteString(fmt.Sprintf("  %s->%s\n", transfer.TransferWith(formula.Left, c.names), transfer.TransferWith(formula.Right, c.names)))
+		build.WriteString(fmt.Sprintf("    because %s\n", c.Derivations[i]))
+	}
+	return build.String()
+}
+
+type cellKey struct {
+	left string
+	set  string
+}
+
+// prediction 产生式预测到某个终结符 以及是否经由 FOLLOW 集
+type prediction struct {
+	formula  *rule.Formula
+	byFollow bool
+}
+
+// newConflict 根据同一格子中的多个预测构造冲突
+func newConflict(firstSet first.FirstSet, rules *rule.Rule, start, left, set string, predictions []*prediction) *Conflict {
+	c := &Conflict{Kind: FirstFirst, Left: left, Lookahead: set, names: rules.Names}
+	for _, p := range predictions {
+		if p.byFollow {
+			c.Kind = FirstFollow
+		}
+		c.Formulas = append(c.Formulas, p.formula)
+		if p.byFollow {
+			c.Derivations = append(c.Derivations, explainFollow(firstSet, rules, start, p.formula, set))
+		} else {
+			c.Derivations = append(c.Derivations, explainFirst(firstSet, rules, p.formula, set))
+		}
+	}
+	return c
+}
+
+// explainFirst 最左推导 A => α =>* aβ
+func explainFirst(firstSet first.FirstSet, rules *rule.Rule, formula *rule.Formula, set string) string {
+	steps := searchLeftmost(firstSet, rules, formula.Right, func(s string) bool {
+		return strings.HasPrefix(s, set)
+	}, func(s string) bool {
+		_, ok := firstSet.FirstOf(s)[set]
+		return ok
+	})
+	if steps == nil {
+		return fmt.Sprintf("%s ∈ FIRST(%s)", rules.Name(set), rules.Name(formula.Right))
+	}
+	return showDerivation(rules, append([]string{formula.Left}, steps...))
+}
+
+// explainFollow α =>* ε 并且 S =>* ...Aa...
+func explainFollow(firstSet first.FirstSet, rules *rule.Rule, start string, formula *rule.Formula, set string) string {
+	var build strings.Builder
+	steps := searchLeftmost(firstSet, rules, formula.Right, func(s string) bool {
+		return s == "&"
+	}, func(s string) bool {
+		_, ok := firstSet.FirstOf(s)["&"]
+		return ok
+	})
+	if steps == nil {
+		build.WriteString(fmt.Sprintf("%s =>* ε", rules.Name(formula.Right)))
+	} else {
+		build.WriteString(showDerivation(rules, append([]string{formula.Left}, steps...)))
+	}
+	build.WriteString(fmt.Sprintf(" and %s ∈ FOLLOW(%s)", rules.Name(set), rules.Name(formula.Left)))
+	if start == "" {
+		return build.String()
+	}
+	// 从开始符号推导出 A 后面紧跟 a 的句型
+	found := searchAny(rules, start, func(s string) bool {
+		for i := 0; i < len(s); i++ {
+			if string(s[i]) != formula.Left {
+				continue
+			}
+			rest := s[i+1:]
+			if _, ok := firstSet.FirstOf(rest)[set]; ok {
+				return true
+			}
+			if _, ok := firstSet.FirstOf(rest)["&"]; ok && set == "#" {
+				return true
+			}
+		}
+		return false
+	})
+	if found != nil {
+		build.WriteString(": " + showDerivation(rules, found))
+		if set == "#" {
+			build.WriteString("#")
+		}
+	}
+	return build.String()
+}
+
+// searchLeftmost 从 from 开始做最左推导 广度优先找到满足 done 的最短推导
+// keep 用于剪枝 不满足的句型不再展开
+func searchLeftmost(firstSet first.FirstSet, rules *rule.Rule, from string, done, keep func(string) bool) []string {
+	type node struct {
+		form string
+		prev *node
+	}
+	queue := []*node{{form: from}}
+	visited := map[string]bool{from: true}
+	for count := 0; len(queue) != 0 && count < maxSearch; count++ {
+		n := queue[0]
+		queue = queue[1:]
+		if done(n.form) {
+			var steps []string
+			for ; n != nil; n = n.prev {
+				steps = append([]string{n.form}, steps...)
+			}
+			return steps
+		}
+		i := strings.IndexFunc(n.form, func(r rune) bool { return r < 128 && !util.IsTerminal(byte(r)) })
+		if i < 0 {
+			continue
+		}
+		for _, right := range rules.Rules[string(n.form[i])] {
+			next := joinForm(n.form[:i], right, n.form[i+1:])
+			if visited[next] || len(next) > len(from)+8 || !keep(next) {
+				continue
+			}
+			visited[next] = true
+			queue = append(queue, &node{form: next, prev: n})
+		}
+	}
+	return nil
+}
+
+// searchAny 从开始符号出发 任意展开非终结符 找到满足 done 的最短推导
+func searchAny(rules *rule.Rule, start string, done func(string) bool) []string {
+	type node struct {
+		form string
+		prev *node
+	}
+	queue := []*node{{form: start}}
+	visited := map[string]bool{start: true}
+	for count := 0; len(queue) != 0 && count < maxSearch; count++ {
+		n := queue[0]
+		queue = queue[1:]
+		if done(n.form) {
+			var steps []string
+			for ; n != nil; n = n.prev {
+				steps = append([]string{n.form}, steps...)
+			}
+			return steps
+		}
+		for i := 0; i < len(n.form); i++ {
+			if util.IsTerminal(n.form[i]) {
+				continue
+			}
+			for _, right := range rules.Rules[string(n.form[i])] {
+				next := joinForm(n.form[:i], right, n.form[i+1:])
+				if visited[next] || len(next) > 12 {
+					continue
+				}
+				visited[next] = true
+				queue = append(queue, &node{form: next, prev: n})
+			}
+		}
+	}
+	return nil
+}
+
+// joinForm 用产生式右部替换句型中的一个非终结符 ε 不出现在句型中
+func joinForm(prefix, right, suffix string) string {
+	if right == "&" {
+		right = ""
+	}
+	if prefix+right+suffix == "" {
+		return "&"
+	}
+	return prefix + right + suffix
+}
+
+// showDerivation 输出推导过程 A => α => ...
+func showDerivation(rules *rule.Rule, steps []string) string {
+	var forms []string
+	for _, step := range steps {
+		forms = append(forms, rules.Name(step))
+	}
+	return strings.Join(forms, " => ")
+}
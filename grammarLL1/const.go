@@ -3,6 +3,7 @@ Package grammarLL1 一个 LL(1) 语法分析器包
 */
 package grammarLL1
 
+import "github.com/esonhugh/compiler/grammarLL1/rule"
 
 // Production 产生式子
 type Production struct {
@@ -11,3 +12,20 @@ type Production struct {
 	Origin string
 	Next   string
 }
+
+// 分析过程中每一步的动作 错误恢复时还有 scan pop insert delete
+const (
+	Derive = "derive" // 用产生式替换栈顶的非终结符
+	Match  = "match"  // 栈顶终结符与当前输入匹配 或者弹出 ε
+	Accept = "accept" // 栈和输入都只剩 #
+	Error  = "error"  // 无法继续分析
+)
+
+// TraceStep 分析过程中的一步 分析栈 栈底在前 剩余输入 动作 以及推导时使用的产生式
+type TraceStep struct {
+	Stack      []string      `json:"stack"`
+	Input      string        `json:"input"`
+	Action     string        `json:"action"`
+	Production *rule.Formula `json:"production,omitempty"`
+	names      map[string]string
+}
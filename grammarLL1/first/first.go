@@ -6,40 +6,97 @@ package first
 import (
 	"github.com/esonhugh/compiler/grammarLL1/rule"
 	"github.com/esonhugh/compiler/grammarLL1/util"
-	"github.com/esonhugh/compiler/util/transfer"
 	"fmt"
 	"strings"
 )
 
 // FirstSet 存储 FIRST 集
-// 一个二维度的表格 存储 FIRST 集
-type FirstSet map[string]map[string]struct{}
+// Sets 为每个非终结符的 FIRST 集 Symbols 决定输出顺序
+type FirstSet struct {
+	Symbols *rule.Symbols
+	Sets    map[string]map[string]struct{}
+}
+
+// Nullable 可以推导出空串 & 的非终结符集合
+type Nullable map[string]bool
+
+// GetNullableSet 不动点迭代求可空非终结符
+// A->& 可空 A->X1X2...Xn 中每个 Xi 都可空时 A 也可空
+func GetNullableSet(rules *rule.Rule) Nullable {
+	nullable := make(Nullable)
+	var changed bool
+	for {
+		changed = false
+		for key, r := range rules.Rules {
+			if nullable[key] {
+				continue
+			}
+			for _, v := range r {
+				if nullable.IsNullable(v) {
+					nullable[key] = true
+					changed = true
+					break
+				}
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+	return nullable
+}
+
+// IsNullable 符号串能否推导出空串
+func (n Nullable) IsNullable(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '&' {
+			continue
+		}
+		if util.IsTerminal(s[i]) || !n[string(s[i])] {
+			return false
+		}
+	}
+	return true
+}
 
 // GetFirstSet 根据规则构建 FIRST 集
+// 产生式右部从左到右扫描 前面的符号可空时继续加入后面符号的 FIRST 集
+// 整个右部都可空时加入 &
 func GetFirstSet(rules *rule.Rule) FirstSet {
-	firstSet := make(FirstSet)
+	firstSet := FirstSet{Symbols: rules.Symbols(), Sets: make(map[string]map[string]struct{})}
+	sets := firstSet.Sets
+	nullable := GetNullableSet(rules)
+	for key := range rules.Rules {
+		sets[key] = make(map[string]struct{})
+		if nullable[key] {
+			sets[key]["&"] = struct{}{}
+		}
+	}
 	var changed bool
 	for {
 		changed = false
 		for key, r := range rules.Rules {
 			// key 左值 r推导值
-			if firstSet[key] == nil {
-				firstSet[key] = make(map[string]struct{})
-			}
 			for _, v := range r {
-				// 遍历产生式
-				// 第一个是终结符,直接将终结符加进first集
-				if util.IsTerminal(v[0]) {
-					// 直接合并
-					if mergeSet(firstSet[key], map[string]struct{}{string(v[0]): {}}) != 0 {
-						changed = true
+				// 遍历产生式 逐个符号处理
+				for i := 0; i < len(v); i++ {
+					if v[i] == '&' {
+						continue
+					}
+					// 终结符 直接将终结符加进first集
+					if util.IsTerminal(v[i]) {
+						if mergeSet(sets[key], map[string]struct{}{string(v[i]): {}}) != 0 {
+							changed = true
+						}
+						break
 					}
-					continue
-				} else {
-					// 第一个是非终结符 去空 合并
-					if removeEmptyAndMergeSet(firstSet[key], firstSet[string(v[0])]) != 0 {
+					// 非终结符 去空 合并
+					if removeEmptyAndMergeSet(sets[key], sets[string(v[i])]) != 0 {
 						changed = true
 					}
+					if !nullable[string(v[i])] {
+						break
+					}
 				}
 			}
 		}
@@ -50,6 +107,28 @@ func GetFirstSet(rules *rule.Rule) FirstSet {
 	return firstSet
 }
 
+// FirstOf 任意符号串 α 的 FIRST(α) α 可空时包含 &
+// 空串和 & 的 FIRST 集为 { & }
+func (f FirstSet) FirstOf(s string) map[string]struct{} {
+	res := make(map[string]struct{})
+	for i := 0; i < len(s); i++ {
+		c := string(s[i])
+		if c == "&" {
+			continue
+		}
+		if util.IsTerminal(s[i]) {
+			res[c] = struct{}{}
+			return res
+		}
+		removeEmptyAndMergeSet(res, f.Sets[c])
+		if !f.haveEmpty(c) {
+			return res
+		}
+	}
+	res["&"] = struct{}{}
+	return res
+}
+
 // removeEmptyAndMergeSet
 // 去掉空终结符 合并 a 和 b 的 map
 // 返回合并变化的个数
@@ -85,31 +164,47 @@ func mergeSet(a map[string]struct{}, b map[string]struct{}) int {
 	return count
 }
 
+// Of 非终结符 key 的 FIRST 集
+func (f FirstSet) Of(key string) map[string]struct{} {
+	return f.Sets[key]
+}
+
+// Elements 按符号编号排序的 FIRST(key)
+func (f FirstSet) Elements(key string) []string {
+	return f.Symbols.Sort(f.Sets[key])
+}
+
 // String 转换为字符串 使得 First 集合可以打印出来
+// 非终结符按声明顺序 集合中的终结符按字典序 # 和 ε 在最后
 func (f FirstSet) String() string {
 	var build strings.Builder
-	for key, value := range f {
-		build.WriteString(fmt.Sprintf("FIRST(%s) = { ", transfer.Transfer(key)))
-		for item := range value {
-			build.WriteString(fmt.Sprintf("%s ", transfer.Transfer(item)))
+	for _, key := range f.keys() {
+		build.WriteString(fmt.Sprintf("FIRST(%s) = { ", f.Symbols.Name(key)))
+		for _, item := range f.Elements(key) {
+			build.WriteString(fmt.Sprintf("%s ", f.Symbols.Name(item)))
 		}
 		build.WriteString("}\n")
 	}
 	return build.String()
 }
 
+// keys 按声明顺序排列的非终结符
+func (f FirstSet) keys() []string {
+	keys := make(map[string]struct{})
+	for key := range f.Sets {
+		keys[key] = struct{}{}
+	}
+	return f.Symbols.Sort(keys)
+}
+
 // haveEmpty 检查 FIRST 集合是否包含空
 func (f FirstSet) haveEmpty(first string) bool {
-	_, ok := f[first]["&"]
+	_, ok := f.Sets[first]["&"]
 	return ok
 }
 
 // IsInFirstSet 检查是否在 FIRST 集合中
 func (f FirstSet) IsInFirstSet(first string, target string) bool {
-	for key := range f[first] {
-		if key == target {
-			return true
-		}
-	}
-	return false
+	_, ok := f.Sets[first][target]
+	return ok
 }
@@ -0,0 +1,156 @@
+package grammarLL1
+
+import (
+	"github.com/esonhugh/compiler/grammarLL1/analysisTable"
+	"github.com/esonhugh/compiler/grammarLL1/first"
+	"github.com/esonhugh/compiler/grammarLL1/follow"
+	"github.com/esonhugh/compiler/grammarLL1/rule"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// textbook 教材中的文法 以及已知的 FIRST FOLLOW 集
+var textbook = []struct {
+	name   string
+	rules  string
+	start  string
+	first  map[string]string
+	follow map[string]string
+}{
+	{
+		name:  "expression",
+		rules: "E->TG\nG->ATG|&\nT->FS\nS->MFS|&\nF->(E)|i\nA->+|-\nM->*|/",
+		start: "E",
+		first: map[string]string{
+			"E": "( i", "G": "& + -", "T": "( i", "S": "& * /", "F": "( i", "A": "+ -", "M": "* /",
+		},
+		follow: map[string]string{
+			"E": "# )", "G": "# )", "T": "# ) + -", "S": "# ) + -", "F": "# ) * + - /", "A": "( i", "M": "( i",
+		},
+	},
+	{
+		name:  "nullable sequence",
+		rules: "A->BC\nB->b|&\nC->c|&",
+		start: "A",
+		first: map[string]string{
+			"A": "& b c", "B": "& b", "C": "& c",
+		},
+		follow: map[string]string{
+			"A": "#", "B": "# c", "C": "#",
+		},
+	},
+	{
+		name:  "nullable prefix",
+		rules: "S->ABCd\nA->a|&\nB->C|b\nC->c|&",
+		start: "S",
+		first: map[string]string{
+			"S": "a b c d", "A": "& a", "B": "& b c", "C": "& c",
+		},
+		follow: map[string]string{
+			"S": "#", "A": "b c d", "B": "c d", "C": "c d",
+		},
+	},
+	{
+		name:  "dangling else",
+		rules: "C->iEtCP|a\nP->eC|&\nE->b",
+		start: "C",
+		first: map[string]string{
+			"C": "a i", "P": "& e", "E": "b",
+		},
+		follow: map[string]string{
+			"C": "# e", "P": "# e", "E": "t",
+		},
+	},
+	{
+		name:  "statement list",
+		rules: "P->DL\nD->dD|&\nL->sL|&",
+		start: "P",
+		first: map[string]string{
+			"P": "& d s", "D": "& d", "L": "& s",
+		},
+		follow: map[string]string{
+			"P": "#", "D": "# s", "L": "#",
+		},
+	},
+}
+
+// setString 集合排序后用空格连接
+func setString(set map[string]struct{}) string {
+	var items []string
+	for key := range set {
+		items = append(items, key)
+	}
+	sort.Strings(items)
+	return strings.Join(items, " ")
+}
+
+func TestFirstFollowTextbook(t *testing.T) {
+	for _, c := range textbook {
+		g := rule.MustParse(c.rules)
+		firstSet := first.GetFirstSet(g)
+		for key, want := range c.first {
+			if got := setString(firstSet.Of(key)); got != want {
+				t.Errorf("%s: FIRST(%s) = { %s }, want { %s }", c.name, key, got, want)
+			}
+		}
+		followSet := follow.GetFollowSet(g, c.start, firstSet)
+		for key, want := range c.follow {
+			if got := setString(followSet.Of(key)); got != want {
+				t.Errorf("%s: FOLLOW(%s) = { %s }, want { %s }", c.name, key, got, want)
+			}
+		}
+	}
+}
+
+func TestFirstOfString(t *testing.T) {
+	g := rule.MustParse("S->ABCd\nA->a|&\nB->C|b\nC->c|&")
+	firstSet := first.GetFirstSet(g)
+	cases := map[string]string{
+		"ABC":  "& a b c",
+		"ABCd": "a b c d",
+		"Cd":   "c d",
+		"":     "&",
+		"&":    "&",
+		"bA":   "b",
+	}
+	for s, want := range cases {
+		if got := setString(firstSet.FirstOf(s)); got != want {
+			t.Errorf("FIRST(%s) = { %s }, want { %s }", s, got, want)
+		}
+	}
+	nullable := first.GetNullableSet(g)
+	if !nullable["A"] || !nullable["B"] || !nullable["C"] || nullable["S"] {
+		t.Errorf("unexpected nullable set %v", nullable)
+	}
+}
+
+func TestDeterministicOutput(t *testing.T) {
+	render := func() string {
+		g := rule.MustParse("%name G E'\nE->TG\nG->+TG|&\nT->(E)|i")
+		firstSet := first.GetFirstSet(g)
+		followSet := follow.GetFollowSet(g, "E", firstSet)
+		table := analysisTable.GetAnalyzeTable(firstSet, followSet, g)
+		return firstSet.String() + followSet.String() + table.String()
+	}
+	want := `FIRST(E) = { ( i }
+FIRST(E') = { + ε }
+FIRST(T) = { ( i }
+FOLLOW(E) = { ) # }
+FOLLOW(E') = { ) # }
+FOLLOW(T) = { ) + # }
++----+--------+-------+----------+--------+-------+
+|    |   (    |   )   |    +     |   i    |   #   |
++----+--------+-------+----------+--------+-------+
+| E  | E->TE' |       |          | E->TE' |       |
+| E' |        | E'->ε | E'->+TE' |        | E'->ε |
+| T  | T->(E) |       |          |  T->i  |       |
++----+--------+-------+----------+--------+-------+
+`
+	// map 的遍历顺序每次都不同 多运行几次
+	for i := 0; i < 20; i++ {
+		if got := render(); got != want {
+			t.Fatalf("got\n%s", got)
+		}
+	}
+}
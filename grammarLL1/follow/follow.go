@@ -8,26 +8,30 @@ import (
 	"github.com/esonhugh/compiler/grammarLL1/first"
 	"github.com/esonhugh/compiler/grammarLL1/rule"
 	"github.com/esonhugh/compiler/grammarLL1/util"
-	"github.com/esonhugh/compiler/util/transfer"
 	"fmt"
 	"strings"
 )
 
 // FollowSet follow集合定义
-type FollowSet map[string]map[string]struct{}
+// Sets 为每个非终结符的 FOLLOW 集 Symbols 决定输出顺序
+type FollowSet struct {
+	Symbols *rule.Symbols
+	Sets    map[string]map[string]struct{}
+}
 
 // GetFollowSet 获取 follow 集合
 // 与 FirstSet 类似 
 func GetFollowSet(rule *rule.Rule, start string, firstSet first.FirstSet) FollowSet {
-	followSet := make(FollowSet)
-	if len(firstSet) == 0 {
+	followSet := FollowSet{Symbols: firstSet.Symbols, Sets: make(map[string]map[string]struct{})}
+	if len(firstSet.Sets) == 0 {
 		return followSet
 	}
-	for key := range firstSet {
-		followSet[key] = make(map[string]struct{})
+	sets := followSet.Sets
+	for key := range firstSet.Sets {
+		sets[key] = make(map[string]struct{})
 	}
 
-	followSet[start]["#"] = struct{}{}
+	sets[start]["#"] = struct{}{}
 
 	var changed bool
 	for {
@@ -35,39 +39,24 @@ func GetFollowSet(rule *rule.Rule, start string, firstSet first.FirstSet) Follow
 
 		for left, right := range rule.Rules {
 			for i := 0; i < len(right); i++ {
-				// 对每一个字符及进行遍历
-				for index, char := range right[i] { //char B
-					if util.IsTerminal(byte(char)) {
+				// 对每一个字符及进行遍历 A->αBβ
+				for index := 0; index < len(right[i]); index++ {
+					char := string(right[i][index])
+					if util.IsTerminal(right[i][index]) {
 						continue
 					}
-					offset := 1
-					for {
-						if index+offset == len(right[i]) { // 到末尾了
-							// A->bB
-							if removeEmptyAndMergeSet(followSet[string(char)], followSet[left]) != 0 {
-								changed = true
-							}
-							break
-						} else { // 未到末尾
-							if util.IsTerminal(right[i][index+offset]) { // A->Bb
-								if mergeSet(followSet[string(char)], map[string]struct{}{string(right[i][index+offset]): {}}) != 0 {
-									changed = true
-								}
-								break
-							} else { // A-> BC
-								if rule.HaveEmptySet(string(right[i][index+offset])) {
-									if removeEmptyAndMergeSet(followSet[string(char)], firstSet[string(right[i][index+offset])]) != 0 {
-										changed = true
-									}
-									offset++
-									continue
-								} else {
-									if removeEmptyAndMergeSet(followSet[string(char)], firstSet[string(right[i][index+offset])]) != 0 {
-										changed = true
-									}
-									break
-								}
-							}
+					if sets[char] == nil {
+						sets[char] = make(map[string]struct{})
+					}
+					// FIRST(β) 去空加入 FOLLOW(B)
+					rest := firstSet.FirstOf(right[i][index+1:])
+					if removeEmptyAndMergeSet(sets[char], rest) != 0 {
+						changed = true
+					}
+					// β 可空 (包括 β 为空串) FOLLOW(A) 加入 FOLLOW(B)
+					if _, ok := rest["&"]; ok {
+						if removeEmptyAndMergeSet(sets[char], sets[left]) != 0 {
+							changed = true
 						}
 					}
 				}
@@ -96,23 +85,33 @@ func removeEmptyAndMergeSet(a map[string]struct{}, b map[string]struct{}) int {
 	return count
 }
 
-func mergeSet(a map[string]struct{}, b map[string]struct{}) int {
-	count := 0
-	for key, value := range b {
-		if _, ok := a[key]; !ok {
-			count++
-		}
-		a[key] = value
-	}
-	return count
+// Of 非终结符 key 的 FOLLOW 集
+func (f FollowSet) Of(key string) map[string]struct{} {
+	return f.Sets[key]
+}
+
+// Contains FOLLOW(key) 中是否有终结符 target
+func (f FollowSet) Contains(key string, target string) bool {
+	_, ok := f.Sets[key][target]
+	return ok
+}
+
+// Elements 按符号编号排序的 FOLLOW(key) # 在最后
+func (f FollowSet) Elements(key string) []string {
+	return f.Symbols.Sort(f.Sets[key])
 }
 
+// String 非终结符按声明顺序输出 与 FirstSet 相同
 func (f FollowSet) String() string {
+	keys := make(map[string]struct{})
+	for key := range f.Sets {
+		keys[key] = struct{}{}
+	}
 	var build strings.Builder
-	for key, value := range f {
-		build.WriteString(fmt.Sprintf("FOLLOW(%s) = { ", transfer.Transfer(key)))
-		for item := range value {
-			build.WriteString(fmt.Sprintf("%s ", transfer.Transfer(item)))
+	for _, key := range f.Symbols.Sort(keys) {
+		build.WriteString(fmt.Sprintf("FOLLOW(%s) = { ", f.Symbols.Name(key)))
+		for _, item := range f.Elements(key) {
+			build.WriteString(fmt.Sprintf("%s ", f.Symbols.Name(item)))
 		}
 		build.WriteString("}\n")
 	}
@@ -2,79 +2,165 @@ package grammarLL1
 
 import (
 	"bytes"
+	"errors"
 	"github.com/esonhugh/compiler/grammarLL1/analysisTable"
 	"github.com/esonhugh/compiler/grammarLL1/first"
 	"github.com/esonhugh/compiler/grammarLL1/follow"
+	"github.com/esonhugh/compiler/grammarLL1/hygiene"
 	"github.com/esonhugh/compiler/grammarLL1/rule"
 	util2 "github.com/esonhugh/compiler/grammarLL1/util"
 	"github.com/esonhugh/compiler/lexer"
 	"github.com/esonhugh/compiler/util"
-	"fmt"
 	"github.com/gookit/color"
 	"io"
+	"strings"
 )
 
 // EndToken 句子结束符号
 const EndToken = "#"
 
 // Grammar 分析器
+// stack 为分析栈 栈底在前 每一步的分析栈 剩余输入和动作记录在 Steps 中
 type Grammar struct {
-	Stream   *util.Stream
+	stack    []string
 	table    analysisTable.SymbolTable
 	endToken string
 	tokens   *util.Queue
+	Steps    Trace
+	// Matcher 词法单元与终结符的对应关系 默认由分析表中的终结符得到 不包含 %token 声明
+	Matcher *rule.Matcher
 }
 
-// Analyze 分析器结果
-func Analyze(raw []*lexer.Token, rules string, start string) ([]*Production, bool) {
-	g := rule.NewRules()
-	_ = g.AddRules(rules)
+// Result LL(1) 分析的全部结果 不输出任何内容 由调用者决定输出哪些
+type Result struct {
+	Report      *hygiene.Report // Report 文法检查结果 规则无法读入时为 nil
+	First       first.FirstSet
+	Follow      follow.FollowSet
+	Table       analysisTable.SymbolTable
+	Conflicts   analysisTable.Conflicts
+	Productions []*Production // Productions 分析得到的最左推导
+	Trace       Trace         // Trace 每一步的分析栈 剩余输入和动作
+	Accepted    bool          // Accepted 输入是否是文法的句子
+}
+
+// Analyze 构造 FIRST FOLLOW 集和分析表并分析输入
+// 文法不能使用或者不是 LL(1) 的时候返回错误 不再用随意选出的分析表进行分析 已经得到的结果仍然放在 Result 中
+func Analyze(raw []*lexer.Token, rules string, start string) (*Result, error) {
+	g, report, err := loadRules(rules, start)
+	res := &Result{Report: report}
+	if err != nil {
+		return res, err
+	}
+	res.First = first.GetFirstSet(g)
+	res.Follow = follow.GetFollowSet(g, start, res.First)
+	res.Table, res.Conflicts = analysisTable.BuildAnalyzeTable(res.First, res.Follow, g, start)
+	if !res.Conflicts.IsLL1() {
+		return res, errors.New("grammar is not LL(1):\n" + res.Conflicts.String())
+	}
+
+	grm := NewGrammar(raw, bytes.NewBufferString(start), EndToken, res.Table)
+	grm.Matcher = g.Matcher()
+	prod := grm.Analyze()
+	res.Productions, res.Trace = prod, grm.Steps
+	res.Accepted = len(prod) != 0 && prod[len(prod)-1].Type == "kill" && prod[len(prod)-1].Target == "#"
+	return res, nil
+}
+
+// IsLL1 判断文法是否是 LL(1) 的 同时返回全部冲突
+func IsLL1(rules string, start string) (bool, analysisTable.Conflicts, error) {
+	_, _, conflicts, err := BuildTable(rules, start)
+	if err != nil {
+		return false, nil, err
+	}
+	return conflicts.IsLL1(), conflicts, nil
+}
+
+// BuildTable 读入规则并构造分析表 不修改文法 不输出任何内容
+// 返回用于构造分析表的规则 供代码生成等工具使用 需要删除无用符号时先调用 StripUseless
+func BuildTable(rules string, start string) (*rule.Rule, analysisTable.SymbolTable, analysisTable.Conflicts, error) {
+	g, _, err := loadRules(rules, start)
+	if err != nil {
+		return nil, analysisTable.SymbolTable{}, nil, err
+	}
 	firstSet := first.GetFirstSet(g)
-	fmt.Println(firstSet.String())
 	followSet := follow.GetFollowSet(g, start, firstSet)
-	fmt.Println(followSet.String())
-	table := analysisTable.GetAnalyzeTable(firstSet, followSet, g)
-	res := table.String()
-	fmt.Println(res)
+	table, conflicts := analysisTable.BuildAnalyzeTable(firstSet, followSet, g, start)
+	return g, table, conflicts, nil
+}
 
-	grm := NewGrammar(raw, bytes.NewBufferString(start), EndToken, table)
-	prod := grm.Analyze()
-	return prod, true
+// StripUseless 删除不可达 不可终止的符号和重复的产生式 返回新的规则文本和检查结果
+// 其他入口都不会修改文法 需要时由调用者先调用这个函数
+func StripUseless(rules string, start string) (string, *hygiene.Report, error) {
+	g, report, err := loadRules(rules, start)
+	if err != nil {
+		return "", report, err
+	}
+	return hygiene.RemoveUseless(g, start).String(), report, nil
 }
 
-// NewGrammar 创建一个新的分析器 
-func NewGrammar(token []*lexer.Token, r io.Reader, et string, rule analysisTable.SymbolTable) *Grammar {
-	s := util.NewStream(r, EndToken)
+// loadRules 读入规则并检查文法 文法保持不变
+// 有未定义的非终结符或者开始符号不可终止时返回错误
+func loadRules(rules string, start string) (*rule.Rule, *hygiene.Report, error) {
+	g := rule.NewRules()
+	if err := g.AddRules(rules); err != nil {
+		return nil, nil, err
+	}
+	report := hygiene.Check(g, start)
+	if !report.IsUsable() {
+		return nil, report, errors.New("grammar is not usable:\n" + report.String())
+	}
+	return g, report, nil
+}
+
+// NewGrammar 创建一个新的分析器 r 中为开始符号
+func NewGrammar(token []*lexer.Token, r io.Reader, et string, table analysisTable.SymbolTable) *Grammar {
+	start, _ := io.ReadAll(r)
+	stack := []string{EndToken}
+	for i := len(start) - 1; i >= 0; i-- {
+		stack = append(stack, string(start[i]))
+	}
 	q := util.New()
 	for i := 0; i < len(token); i++ {
 		q.PushBack(token[i])
 	}
-	q.PushBack(&lexer.Token{
-		Typ:   lexer.END,
-		Value: "#",
-	})
-	return &Grammar{Stream: s, endToken: et, table: rule, tokens: q}
+	// 结束符的位置在最后一个词法单元之后 用于报告输入提前结束的错误
+	end := &lexer.Token{
+		Typ:    lexer.END,
+		Value:  "#",
+		Row:    1,
+		Column: 1,
+	}
+	if len(token) > 0 {
+		last := token[len(token)-1]
+		end.Row, end.Column = last.Row, last.Column+len(last.Value)
+	}
+	q.PushBack(end)
+	return &Grammar{stack: stack, endToken: et, table: table, tokens: q, Matcher: rule.NewMatcher(table.Terminals, nil)}
 }
 
 // Analyze 分析
 func (g *Grammar) Analyze() (res []*Production) {
 	for {
 		// 语法分析
-		if g.tokens.Front().(*lexer.Token).Typ == lexer.END && g.Stream.Peek() == "#" {
+		if g.tokens.Front().(*lexer.Token).Typ == lexer.END && g.top() == "#" {
+			g.record(Accept, nil)
 			res = append(res, &Production{
 				Type:   "kill",
 				Target: "#",
 			})
 			break
 		}
-		if g.tokens.Front().(*lexer.Token).Typ != lexer.END && g.Stream.Peek() == "#" {
+		if g.tokens.Front().(*lexer.Token).Typ != lexer.END && g.top() == "#" {
+			g.record(Error, nil)
 			color.Redln("Wrong grammar")
 			break
 		}
 
-		ProcessC := g.Stream.Next()
+		ProcessC := g.top()
 		TargetC := g.tokens.Front().(*lexer.Token)
 		if ProcessC == "&" {
+			g.record(Match, nil)
+			g.pop()
 			res = append(res, &Production{
 				Type:   "kill",
 				Target: ProcessC,
@@ -82,7 +168,9 @@ func (g *Grammar) Analyze() (res []*Production) {
 			continue
 		}
 		if util2.IsTerminal(ProcessC[0]) {
-			if ProcessC == TargetC.Value || (ProcessC == "i" && TargetC.Typ == lexer.VARIABLE) {
+			if g.Matcher.Match(ProcessC, TargetC) {
+				g.record(Match, nil)
+				g.pop()
 				res = append(res, &Production{
 					Type:   "kill",
 					Target: TargetC.Value,
@@ -90,18 +178,20 @@ func (g *Grammar) Analyze() (res []*Production) {
 				g.tokens.Pop()
 				continue
 			} else {
+				g.record(Error, nil)
 				color.Redln("Wrong grammar")
 				return
 			}
 		} else {
-			proc := g.table[ProcessC][TargetC.Value]
+			proc := g.table.Get(ProcessC, g.Matcher.TerminalOf(TargetC))
 			if proc == nil {
+				g.record(Error, nil)
 				color.Redln("Wrong grammar")
 				return
 			}
-			for i := 0; i < len(proc.Right); i++ {
-				g.Stream.PutBack(string(proc.Right[len(proc.Right)-1-i]))
-			}
+			g.record(Derive, proc)
+			g.pop()
+			g.push(proc.Right)
 			res = append(res, &Production{
 				Type:   "Continue",
 				Origin: proc.Left,
@@ -111,3 +201,38 @@ func (g *Grammar) Analyze() (res []*Production) {
 	}
 	return
 }
+
+// top 栈顶符号
+func (g *Grammar) top() string {
+	return g.stack[len(g.stack)-1]
+}
+
+// pop 弹出栈顶符号
+func (g *Grammar) pop() {
+	g.stack = g.stack[:len(g.stack)-1]
+}
+
+// push 把产生式右部倒序压栈
+func (g *Grammar) push(right string) {
+	for i := len(right) - 1; i >= 0; i-- {
+		g.stack = append(g.stack, string(right[i]))
+	}
+}
+
+// record 记录当前的分析栈 剩余输入和将要执行的动作
+func (g *Grammar) record(action string, formula *rule.Formula) {
+	var input strings.Builder
+	for e := g.tokens.FrontRaw(); e != nil; e = e.Next() {
+		input.WriteString(e.Value.(*lexer.Token).Value)
+	}
+	step := &TraceStep{
+		Stack:      append([]string(nil), g.stack...),
+		Input:      input.String(),
+		Action:     action,
+		Production: formula,
+	}
+	if g.table.Symbols != nil {
+		step.names = g.table.Symbols.Names
+	}
+	g.Steps = append(g.Steps, step)
+}
@@ -0,0 +1,242 @@
+/*
+Package hygiene 文法检查包 找出未定义 不可达 不可终止的符号以及重复的产生式
+
+AddRules 输入中的拼写错误只会表现为错误的分析表 在构造 FIRST FOLLOW 集之前先做检查
+*/
+package hygiene
+
+import (
+	"fmt"
+	"github.com/esonhugh/compiler/grammarLL1/rule"
+	"github.com/esonhugh/compiler/grammarLL1/util"
+	"github.com/esonhugh/compiler/util/transfer"
+	"sort"
+	"strings"
+)
+
+// Report 文法检查结果
+type Report struct {
+	Start        string
+	Undefined    []string          // Undefined 出现在右部但是没有产生式的非终结符
+	Unreachable  []string          // Unreachable 从开始符号不可达的符号
+	Unproductive []string          // Unproductive 推导不出终结符串的非终结符
+	Duplicates   []*rule.Formula   // Duplicates 重复的产生式
+	Names        map[string]string // Names 文法的显示名
+}
+
+// IsClean 文法没有任何问题
+func (r *Report) IsClean() bool {
+	return len(r.Undefined) == 0 && len(r.Unreachable) == 0 && len(r.Unproductive) == 0 && len(r.Duplicates) == 0
+}
+
+// IsUsable 文法能否继续分析 有未定义的符号或者开始符号不可终止时不能
+func (r *Report) IsUsable() bool {
+	if len(r.Undefined) != 0 {
+		return false
+	}
+	for _, sym := range r.Unproductive {
+		if sym == r.Start {
+			return false
+		}
+	}
+	return true
+}
+
+// String 输出检查结果
+func (r *Report) String() string {
+	if r.IsClean() {
+		return "grammar is clean\n"
+	}
+	var build strings.Builder
+	if len(r.Undefined) != 0 {
+		build.WriteString(fmt.Sprintf("undefined nonterminals: %s\n", symbols(r.Undefined, r.Names)))
+	}
+	if len(r.Unreachable) != 0 {
+		build.WriteString(fmt.Sprintf("unreachable symbols: %s\n", symbols(r.Unreachable, r.Names)))
+	}
+	if len(r.Unproductive) != 0 {
+		build.WriteString(fmt.Sprintf("unproductive nonterminals: %s\n", symbols(r.Unproductive, r.Names)))
+	}
+	for _, f := range r.Duplicates {
+		build.WriteString(fmt.Sprintf("duplicate production: %s->%s\n", transfer.TransferWith(f.Left, r.Names), transfer.TransferWith(f.Right, r.Names)))
+	}
+	return build.String()
+}
+
+// Check 检查文法 start 为开始符号
+func Check(r *rule.Rule, start string) *Report {
+	report := &Report{Start: start, Names: r.Names}
+
+	// 未定义的非终结符
+	seen := make(map[string]bool)
+	if _, ok := r.Rules[start]; !ok {
+		report.Undefined = append(report.Undefined, start)
+		seen[start] = true
+	}
+	for _, f := range r.Formulas() {
+		for i := 0; i < len(f.Right); i++ {
+			c := string(f.Right[i])
+			if util.IsTerminal(f.Right[i]) || seen[c] {
+				continue
+			}
+			if _, ok := r.Rules[c]; !ok {
+				report.Undefined = append(report.Undefined, c)
+				seen[c] = true
+			}
+		}
+	}
+
+	// 不可终止的非终结符 未定义的符号已经单独报告
+	productive := Productive(r)
+	for _, left := range r.Nonterminals() {
+		if !productive[left] {
+			report.Unproductive = append(report.Unproductive, left)
+		}
+	}
+
+	// 不可达的符号 包括只出现在不可达产生式中的终结符
+	reachable := Reachable(r, start)
+	for _, sym := range allSymbols(r) {
+		if !reachable[sym] {
+			report.Unreachable = append(report.Unreachable, sym)
+		}
+	}
+
+	// 重复的产生式
+	for _, left := range r.Nonterminals() {
+		count := make(map[string]int)
+		for _, right := range r.Rules[left] {
+			count[right]++
+			if count[right] == 2 {
+				report.Duplicates = append(report.Duplicates, &rule.Formula{Left: left, Right: right})
+			}
+		}
+	}
+	return report
+}
+
+// Productive 能推导出终结符串的非终结符 不动点迭代
+func Productive(r *rule.Rule) map[string]bool {
+	productive := make(map[string]bool)
+	for changed := true; changed; {
+		changed = false
+		for left, rights := range r.Rules {
+			if productive[left] {
+				continue
+			}
+			for _, right := range rights {
+				if isProductive(right, productive) {
+					productive[left] = true
+					changed = true
+					break
+				}
+			}
+		}
+	}
+	return productive
+}
+
+// Reachable 从开始符号可达的全部符号
+func Reachable(r *rule.Rule, start string) map[string]bool {
+	reachable := map[string]bool{start: true}
+	queue := []string{start}
+	for len(queue) != 0 {
+		left := queue[0]
+		queue = queue[1:]
+		for _, right := range r.Rules[left] {
+			for i := 0; i < len(right); i++ {
+				c := string(right[i])
+				if c == "&" || reachable[c] {
+					continue
+				}
+				reachable[c] = true
+				if !util.IsTerminal(right[i]) {
+					queue = append(queue, c)
+				}
+			}
+		}
+	}
+	return reachable
+}
+
+// RemoveUseless 删除无用符号和重复的产生式 返回新的规则 不修改原始规则
+// 先删除含有不可终止符号的产生式 再删除不可达的非终结符 顺序不能颠倒
+func RemoveUseless(r *rule.Rule, start string) *rule.Rule {
+	productive := Productive(r)
+	g := rule.NewRules()
+	for _, left := range r.Nonterminals() {
+		if !productive[left] {
+			continue
+		}
+		for _, right := range r.Rules[left] {
+			if isProductive(right, productive) && !contains(g.Rules[left], right) {
+				g.AddFormula(left, right)
+			}
+		}
+	}
+	reachable := Reachable(g, start)
+	res := rule.NewRules()
+	res.Precedences = r.Precedences
+	res.Prec = r.Prec
+	res.Tokens = r.Tokens
+	res.Names = r.Names
+	for _, left := range g.Nonterminals() {
+		if !reachable[left] {
+			continue
+		}
+		for _, right := range g.Rules[left] {
+			res.AddFormula(left, right)
+		}
+	}
+	return res
+}
+
+// isProductive 右部每个非终结符都可终止
+func isProductive(right string, productive map[string]bool) bool {
+	for i := 0; i < len(right); i++ {
+		if !util.IsTerminal(right[i]) && !productive[string(right[i])] {
+			return false
+		}
+	}
+	return true
+}
+
+// allSymbols 文法中出现的全部符号 非终结符按声明顺序在前 终结符排序在后
+func allSymbols(r *rule.Rule) []string {
+	res := r.Nonterminals()
+	seen := make(map[string]bool)
+	for _, left := range res {
+		seen[left] = true
+	}
+	var terminals []string
+	for _, f := range r.Formulas() {
+		for i := 0; i < len(f.Right); i++ {
+			c := string(f.Right[i])
+			if c == "&" || seen[c] || !util.IsTerminal(f.Right[i]) {
+				continue
+			}
+			seen[c] = true
+			terminals = append(terminals, c)
+		}
+	}
+	sort.Strings(terminals)
+	return append(res, terminals...)
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// symbols 输出符号列表
+func symbols(list []string, names map[string]string) string {
+	var res []string
+	for _, sym := range list {
+		res = append(res, transfer.TransferWith(sym, names))
+	}
+	return strings.Join(res, " ")
+}
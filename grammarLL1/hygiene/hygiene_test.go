@@ -0,0 +1,59 @@
+package hygiene
+
+import (
+	"github.com/esonhugh/compiler/grammarLL1/rule"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	// D 未定义 (拼写错误) B 不可终止 C 不可达 F->i 重复
+	g := rule.MustParse("E->TB|T\nT->F|Fd|D\nB->aB\nC->c\nF->i|i")
+	report := Check(g, "E")
+	t.Log(report.String())
+	if strings.Join(report.Undefined, " ") != "D" {
+		t.Errorf("undefined = %v", report.Undefined)
+	}
+	if strings.Join(report.Unproductive, " ") != "B" {
+		t.Errorf("unproductive = %v", report.Unproductive)
+	}
+	if strings.Join(report.Unreachable, " ") != "C c" {
+		t.Errorf("unreachable = %v", report.Unreachable)
+	}
+	if len(report.Duplicates) != 1 || report.Duplicates[0].Right != "i" {
+		t.Errorf("duplicates = %v", report.Duplicates)
+	}
+	if report.IsUsable() {
+		t.Error("grammar with undefined nonterminal should not be usable")
+	}
+}
+
+func TestRemoveUseless(t *testing.T) {
+	// 先删不可终止再删不可达: A 只出现在含有不可终止符号 B 的产生式中
+	g := rule.MustParse("S->AB|a\nA->a\nB->bB")
+	res := RemoveUseless(g, "S")
+	if res.String() != "S->a\n" {
+		t.Fatalf("got\n%s", res.String())
+	}
+	if !Check(res, "S").IsClean() {
+		t.Fatal("stripped grammar should be clean")
+	}
+	if !Check(g, "S").IsUsable() {
+		t.Fatal("start symbol is productive")
+	}
+}
+
+func TestNames(t *testing.T) {
+	// 没有 %name 声明时 S 按原样输出
+	g := rule.MustParse("S->aS|X")
+	if report := Check(g, "S").String(); report != "undefined nonterminals: X\nunproductive nonterminals: S\n" {
+		t.Fatalf("got\n%s", report)
+	}
+	g = rule.MustParse("%name S T'\nS->aS|a|a|B\nB->B")
+	if report := Check(g, "S").String(); !strings.Contains(report, "duplicate production: T'->a") {
+		t.Fatalf("got\n%s", report)
+	}
+	if res := RemoveUseless(g, "S"); res.String() != "%name S T'\nS->aS|a\n" {
+		t.Fatalf("got\n%s", res.String())
+	}
+}
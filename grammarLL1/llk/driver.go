@@ -0,0 +1,117 @@
+package llk
+
+import (
+	"fmt"
+	"github.com/esonhugh/compiler/grammarLL1"
+	"github.com/esonhugh/compiler/grammarLL1/rule"
+	"github.com/esonhugh/compiler/grammarLL1/util"
+	"github.com/esonhugh/compiler/lexer"
+	"github.com/gookit/color"
+)
+
+// Grammar LL(k) 分析器 每次向前看 k 个词法单元选择产生式
+type Grammar struct {
+	table   *Table
+	stack   []string
+	tokens  []*lexer.Token
+	pos     int
+	matcher *rule.Matcher
+}
+
+// Analyze 分析器结果 k 为向前看的词法单元个数
+func Analyze(raw []*lexer.Token, rules string, start string, k int) ([]*grammarLL1.Production, bool) {
+	g := rule.NewRules()
+	if err := g.AddRules(rules); err != nil {
+		color.Redln(err.Error())
+		return nil, false
+	}
+	table, conflicts := GetLLkTable(g, start, k)
+	fmt.Println(table.String())
+	if len(conflicts) != 0 {
+		for _, c := range conflicts {
+			color.Red.Print(c.String())
+		}
+		return nil, false
+	}
+	return NewGrammar(raw, table).Analyze()
+}
+
+// NewGrammar 创建一个新的 LL(k) 分析器
+func NewGrammar(token []*lexer.Token, table *Table) *Grammar {
+	tokens := append([]*lexer.Token(nil), token...)
+	tokens = append(tokens, &lexer.Token{
+		Typ:   lexer.END,
+		Value: EndToken,
+	})
+	return &Grammar{
+		table:   table,
+		stack:   []string{EndToken, table.Start},
+		tokens:  tokens,
+		matcher: table.rules.Matcher(),
+	}
+}
+
+// Analyze 分析 结果与 grammarLL1 的分析器相同 可以直接用 PrintGrammarLL1 输出
+func (g *Grammar) Analyze() (res []*grammarLL1.Production, ok bool) {
+	for len(g.stack) != 0 {
+		top := g.stack[len(g.stack)-1]
+		g.stack = g.stack[:len(g.stack)-1]
+		current := g.matcher.TerminalOf(g.tokens[g.pos])
+
+		if top == EndToken {
+			if current != EndToken {
+				break
+			}
+			res = append(res, &grammarLL1.Production{
+				Type:   "kill",
+				Target: EndToken,
+			})
+			return res, true
+		}
+		if top == "&" {
+			res = append(res, &grammarLL1.Production{
+				Type:   "kill",
+				Target: top,
+			})
+			continue
+		}
+		if util.IsTerminal(top[0]) {
+			if top != current {
+				break
+			}
+			res = append(res, &grammarLL1.Production{
+				Type:   "kill",
+				Target: top,
+			})
+			g.pos++
+			continue
+		}
+		proc := g.table.Cells[top][g.lookahead()]
+		if proc == nil {
+			break
+		}
+		for i := len(proc.Right) - 1; i >= 0; i-- {
+			g.stack = append(g.stack, string(proc.Right[i]))
+		}
+		res = append(res, &grammarLL1.Production{
+			Type:   "Continue",
+			Origin: proc.Left,
+			Next:   proc.Right,
+		})
+	}
+	color.Redln("Wrong grammar")
+	return res, false
+}
+
+// lookahead 接下来 k 个词法单元对应的终结符串 遇到 # 或者没有对应终结符的词法单元为止
+func (g *Grammar) lookahead() string {
+	s := ""
+	for i := g.pos; i < len(g.tokens) && len(s) < g.table.K; i++ {
+		t := g.matcher.TerminalOf(g.tokens[i])
+		s += t
+		if t == EndToken || t == "" {
+			break
+		}
+	}
+	return s
+}
@@ -0,0 +1,93 @@
+package llk
+
+import (
+	"github.com/esonhugh/compiler/grammarLL1/analysisTable"
+	"github.com/esonhugh/compiler/grammarLL1/first"
+	"github.com/esonhugh/compiler/grammarLL1/follow"
+	"github.com/esonhugh/compiler/grammarLL1/rule"
+	"github.com/esonhugh/compiler/lexer"
+	"strings"
+	"testing"
+)
+
+// 赋值和调用 需要看两个词法单元 i= 和 i( 才能区分
+const assignOrCall = "P->i=E|i(E)\nE->i|n"
+
+func TestFirstK(t *testing.T) {
+	g := rule.MustParse(assignOrCall)
+	firstK := GetFirstKSet(g, 2)
+	t.Log(firstK.String("FIRST_2", g))
+	if got := strings.Join(sortedKeys(g.Symbols(), firstK["P"]), " "); got != "i( i=" {
+		t.Fatalf("FIRST_2(P) = { %s }", got)
+	}
+	followK := GetFollowKSet(g, "P", 2, firstK)
+	t.Log(followK.String("FOLLOW_2", g))
+	if got := strings.Join(sortedKeys(g.Symbols(), followK["E"]), " "); got != ")# #" {
+		t.Fatalf("FOLLOW_2(E) = { %s }", got)
+	}
+}
+
+func TestSmallestK(t *testing.T) {
+	if k, ok := SmallestK(rule.MustParse(assignOrCall), "P", 3); !ok || k != 2 {
+		t.Fatalf("assignment or call should be strong-LL(2), got %d %v", k, ok)
+	}
+	if k, ok := SmallestK(rule.MustParse("E->TG\nG->ATG|&\nT->FS\nS->MFS|&\nF->(E)|i\nA->+|-\nM->*|/"), "E", 3); !ok || k != 1 {
+		t.Fatalf("expression grammar should be LL(1), got %d %v", k, ok)
+	}
+	// a^n b 和 a^n c 对任何 k 都无法区分
+	if _, ok := SmallestK(rule.MustParse("S->Ab|Bc\nA->aA|a\nB->aB|a"), "S", 4); ok {
+		t.Fatal("grammar is not LL(k) for any k")
+	}
+}
+
+func TestNames(t *testing.T) {
+	// 没有 %name 声明时 S 按原样输出
+	g := rule.MustParse("S->Ab|Ac\nA->a")
+	firstK := GetFirstKSet(g, 1)
+	if got := firstK.String("FIRST_1", g); !strings.HasPrefix(got, "FIRST_1(S) = { a }") {
+		t.Fatalf("got\n%s", got)
+	}
+	_, conflicts := GetLLkTable(g, "S", 1)
+	if len(conflicts) != 1 || conflicts[0].String() != "conflict at M[S, a]: S->Ab | S->Ac\n" {
+		t.Fatalf("got %v", conflicts)
+	}
+	g = rule.MustParse("%name S T'\nS->Ab|Ac\nA->a")
+	table, conflicts := GetLLkTable(g, "S", 2)
+	if len(conflicts) != 0 || !strings.Contains(table.String(), "T'->Ab") {
+		t.Fatalf("got\n%s", table.String())
+	}
+}
+
+func TestLL1TableMatches(t *testing.T) {
+	g := rule.MustParse("E->TG\nG->ATG|&\nT->FS\nS->MFS|&\nF->(E)|i\nA->+|-\nM->*|/")
+	table, conflicts := GetLLkTable(g, "E", 1)
+	if len(conflicts) != 0 {
+		t.Fatal("unexpected conflicts")
+	}
+	firstSet := first.GetFirstSet(g)
+	ll1 := analysisTable.GetAnalyzeTable(firstSet, follow.GetFollowSet(g, "E", firstSet), g)
+	for left, row := range ll1.Cells {
+		for set, formula := range row {
+			got := table.Cells[left][set]
+			if (formula == nil) != (got == nil) || (formula != nil && formula.Right != got.Right) {
+				t.Errorf("M[%s, %s] differs: %v %v", left, set, formula, got)
+			}
+		}
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	for _, code := range []string{"a=b", "a(b)", "a(n)"} {
+		if _, ok := Analyze(lexer.Analyse(code), assignOrCall, "P", 2); !ok {
+			t.Errorf("%s should be accepted", code)
+		}
+	}
+	for _, code := range []string{"a)b", "a=", "a(b"} {
+		if _, ok := Analyze(lexer.Analyse(code), assignOrCall, "P", 2); ok {
+			t.Errorf("%s should be rejected", code)
+		}
+	}
+	if _, ok := Analyze(lexer.Analyse("a=b"), assignOrCall, "P", 1); ok {
+		t.Error("LL(1) table has conflicts")
+	}
+}
@@ -0,0 +1,177 @@
+/*
+Package llk LL(k) 分析包 FIRST_k FOLLOW_k 集 以 k 个终结符组成的向前看串为键的分析表和对应的分析器
+
+向前看串直接用终结符拼接而成的字符串表示 空串 "" 表示 ε
+串中出现结束符号 # 之后不再延长 因此长度可能小于 k
+分析表按 strong-LL(k) 的定义构造 k=1 时与 LL(1) 分析表相同
+*/
+package llk
+
+import (
+	"fmt"
+	"github.com/esonhugh/compiler/grammarLL1/rule"
+	"github.com/esonhugh/compiler/grammarLL1/util"
+	"github.com/esonhugh/compiler/util/transfer"
+	"sort"
+	"strings"
+)
+
+// EndToken 句子结束符号
+const EndToken = "#"
+
+// KSet 每个非终结符对应的向前看串集合 FIRST_k 和 FOLLOW_k 共用
+type KSet map[string]map[string]struct{}
+
+// GetFirstKSet 不动点迭代求 FIRST_k
+func GetFirstKSet(rules *rule.Rule, k int) KSet {
+	firstK := make(KSet)
+	for key := range rules.Rules {
+		firstK[key] = make(map[string]struct{})
+	}
+	var changed bool
+	for {
+		changed = false
+		for key, r := range rules.Rules {
+			for _, v := range r {
+				if mergeSet(firstK[key], FirstKOf(firstK, v, k)) != 0 {
+					changed = true
+				}
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+	return firstK
+}
+
+// GetFollowKSet 求 FOLLOW_k 开始符号的 FOLLOW_k 包含 #
+// B->αAβ 则 FIRST_k(β) ⊕k FOLLOW_k(B) 加入 FOLLOW_k(A)
+func GetFollowKSet(rules *rule.Rule, start string, k int, firstK KSet) KSet {
+	followK := make(KSet)
+	for key := range rules.Rules {
+		followK[key] = make(map[string]struct{})
+	}
+	if followK[start] == nil {
+		followK[start] = make(map[string]struct{})
+	}
+	followK[start][EndToken] = struct{}{}
+	var changed bool
+	for {
+		changed = false
+		for left, r := range rules.Rules {
+			for _, v := range r {
+				for i := 0; i < len(v); i++ {
+					if util.IsTerminal(v[i]) {
+						continue
+					}
+					char := string(v[i])
+					if followK[char] == nil {
+						followK[char] = make(map[string]struct{})
+					}
+					rest := concatK(FirstKOf(firstK, v[i+1:], k), followK[left], k)
+					if mergeSet(followK[char], rest) != 0 {
+						changed = true
+					}
+				}
+			}
+		}
+		if !changed {
+			break
+		}
+	}
+	return followK
+}
+
+// FirstKOf 符号串的 FIRST_k 集
+func FirstKOf(firstK KSet, s string, k int) map[string]struct{} {
+	res := map[string]struct{}{"": {}}
+	for i := 0; i < len(s); i++ {
+		if s[i] == '&' {
+			continue
+		}
+		if util.IsTerminal(s[i]) {
+			res = concatK(res, map[string]struct{}{string(s[i]): {}}, k)
+		} else {
+			res = concatK(res, firstK[string(s[i])], k)
+		}
+		if len(res) == 0 || complete(res, k) {
+			break
+		}
+	}
+	return res
+}
+
+// concatK k 前缀连接 a ⊕k b = { (xy)[:k] }
+func concatK(a, b map[string]struct{}, k int) map[string]struct{} {
+	res := make(map[string]struct{})
+	for x := range a {
+		if len(x) >= k || strings.HasSuffix(x, EndToken) {
+			res[x] = struct{}{}
+			continue
+		}
+		for y := range b {
+			xy := x + y
+			if len(xy) > k {
+				xy = xy[:k]
+			}
+			res[xy] = struct{}{}
+		}
+	}
+	return res
+}
+
+// complete 集合中的串都已经不能再延长
+func complete(set map[string]struct{}, k int) bool {
+	for x := range set {
+		if len(x) < k && !strings.HasSuffix(x, EndToken) {
+			return false
+		}
+	}
+	return true
+}
+
+func mergeSet(a map[string]struct{}, b map[string]struct{}) int {
+	count := 0
+	for key, value := range b {
+		if _, ok := a[key]; !ok {
+			count++
+		}
+		a[key] = value
+	}
+	return count
+}
+
+// sortedKeys 按符号编号排序后的集合元素 # 排在其他终结符之后
+func sortedKeys(symbols *rule.Symbols, set map[string]struct{}) []string {
+	var res []string
+	for key := range set {
+		res = append(res, key)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return symbols.LessString(res[i], res[j])
+	})
+	return res
+}
+
+// show 向前看串的显示形式 ε 表示空串
+func show(s string, names map[string]string) string {
+	if s == "" {
+		return "ε"
+	}
+	return transfer.TransferWith(s, names)
+}
+
+// String 输出集合 name 为 FIRST_k 或 FOLLOW_k
+func (s KSet) String(name string, rules *rule.Rule) string {
+	var build strings.Builder
+	symbols := rules.Symbols()
+	for _, key := range rules.Nonterminals() {
+		build.WriteString(fmt.Sprintf("%s(%s) = { ", name, rules.Name(key)))
+		for _, item := range sortedKeys(symbols, s[key]) {
+			build.WriteString(show(item, rules.Names) + " ")
+		}
+		build.WriteString("}\n")
+	}
+	return build.String()
+}
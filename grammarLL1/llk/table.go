@@ -0,0 +1,114 @@
+package llk
+
+import (
+	"fmt"
+	"github.com/esonhugh/compiler/grammarLL1/rule"
+	"github.com/esonhugh/compiler/util/transfer"
+	"github.com/liushuochen/gotable"
+	"strings"
+)
+
+// Table LL(k) 分析表 M[A][u] u 为向前看串
+type Table struct {
+	K     int
+	Start string
+	Cells map[string]map[string]*rule.Formula
+	rules *rule.Rule
+}
+
+// Conflict 同一个向前看串预测了多个产生式
+type Conflict struct {
+	Left      string
+	Lookahead string
+	Formulas  []*rule.Formula
+	names     map[string]string
+}
+
+// String 输出冲突
+func (c *Conflict) String() string {
+	var rights []string
+	for _, f := range c.Formulas {
+		rights = append(rights, fmt.Sprintf("%s->%s", transfer.TransferWith(f.Left, c.names), transfer.TransferWith(f.Right, c.names)))
+	}
+	return fmt.Sprintf("conflict at M[%s, %s]: %s\n", transfer.TransferWith(c.Left, c.names), show(c.Lookahead, c.names), strings.Join(rights, " | "))
+}
+
+// GetLLkTable 构造 strong-LL(k) 分析表
+// A->α 填入所有 u ∈ FIRST_k(α FOLLOW_k(A)) 的格子 冲突时保留先声明的产生式
+func GetLLkTable(rules *rule.Rule, start string, k int) (*Table, []*Conflict) {
+	firstK := GetFirstKSet(rules, k)
+	followK := GetFollowKSet(rules, start, k, firstK)
+	table := &Table{K: k, Start: start, Cells: make(map[string]map[string]*rule.Formula), rules: rules}
+	var conflicts []*Conflict
+	symbols := rules.Symbols()
+	for _, left := range rules.Nonterminals() {
+		table.Cells[left] = make(map[string]*rule.Formula)
+		predicted := make(map[string][]*rule.Formula)
+		for _, right := range rules.Rules[left] {
+			formula := &rule.Formula{Left: left, Right: right}
+			for u := range concatK(FirstKOf(firstK, right, k), followK[left], k) {
+				predicted[u] = append(predicted[u], formula)
+			}
+		}
+		for _, u := range sortedKeys(symbols, toSet(predicted)) {
+			table.Cells[left][u] = predicted[u][0]
+			if len(predicted[u]) > 1 {
+				conflicts = append(conflicts, &Conflict{Left: left, Lookahead: u, Formulas: predicted[u], names: rules.Names})
+			}
+		}
+	}
+	return table, conflicts
+}
+
+// SmallestK 找出文法是 strong-LL(k) 的最小 k 不超过 bound
+// 超过 bound 仍有冲突时返回 false
+func SmallestK(rules *rule.Rule, start string, bound int) (int, bool) {
+	for k := 1; k <= bound; k++ {
+		if _, conflicts := GetLLkTable(rules, start, k); len(conflicts) == 0 {
+			return k, true
+		}
+	}
+	return 0, false
+}
+
+// String 输出分析表 与 analysisTable.SymbolTable 一样使用 gotable
+func (t *Table) String() string {
+	columns := make(map[string]struct{})
+	for _, row := range t.Cells {
+		for u := range row {
+			columns[u] = struct{}{}
+		}
+	}
+	column := []string{" "}
+	for _, u := range sortedKeys(t.rules.Symbols(), columns) {
+		column = append(column, show(u, t.rules.Names))
+	}
+	table, err := gotable.Create(column...)
+	if err != nil {
+		fmt.Println(err.Error())
+		return ""
+	}
+	for _, left := range t.rules.Nonterminals() {
+		row := make(map[string]string)
+		row[" "] = t.rules.Name(left)
+		for u := range columns {
+			row[show(u, t.rules.Names)] = ""
+			if formula := t.Cells[left][u]; formula != nil {
+				row[show(u, t.rules.Names)] = fmt.Sprintf("%s->%s", t.rules.Name(formula.Left), t.rules.Name(formula.Right))
+			}
+		}
+		if err = table.AddRow(row); err != nil {
+			fmt.Println(err.Error())
+			return ""
+		}
+	}
+	return table.String()
+}
+
+func toSet(m map[string][]*rule.Formula) map[string]struct{} {
+	res := make(map[string]struct{})
+	for key := range m {
+		res[key] = struct{}{}
+	}
+	return res
+}
@@ -0,0 +1,192 @@
+package grammarLL1
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/esonhugh/compiler/grammarLL1/analysisTable"
+	"github.com/esonhugh/compiler/grammarLL1/first"
+	"github.com/esonhugh/compiler/grammarLL1/follow"
+	util2 "github.com/esonhugh/compiler/grammarLL1/util"
+	"github.com/esonhugh/compiler/lexer"
+	"strings"
+)
+
+// 错误恢复的方式
+const (
+	// PanicMode 紧急方式 空白格子中 FOLLOW 集里的符号为 pop 其余为 scan 栈顶终结符不匹配时弹出
+	PanicMode = "panic"
+	// PhraseLevel 短语级恢复 先尝试删除一个多余的词法单元或者补上一个缺少的终结符 失败时再按紧急方式处理
+	PhraseLevel = "phrase"
+)
+
+// 恢复时采取的动作
+const (
+	Scan   = "scan"   // 跳过当前的词法单元
+	Pop    = "pop"    // 弹出栈顶的非终结符 即认为它已经分析完
+	Insert = "insert" // 补上栈顶缺少的终结符
+	Delete = "delete" // 删除一个多余的词法单元
+)
+
+// Diagnostic 一个已经恢复的语法错误
+// Row 和 Column 为出错的词法单元的行和列 输入结束时为最后一个词法单元之后的位置
+type Diagnostic struct {
+	Row      int
+	Column   int
+	Found    *lexer.Token
+	Expected []string
+	Action   string
+	Symbol   string
+}
+
+// String 输出错误 line 1 col 3: expected ( or i, found '+' (scan +)
+func (d *Diagnostic) String() string {
+	found := "end of input"
+	if d.Found.Typ != lexer.END {
+		found = "'" + d.Found.Value + "'"
+	}
+	return fmt.Sprintf("line %d col %d: expected %s, found %s (%s %s)", d.Row, d.Column, strings.Join(d.Expected, " or "), found, d.Action, d.Symbol)
+}
+
+// Diagnostics 全部错误
+type Diagnostics []*Diagnostic
+
+// String 每行一个错误
+func (ds Diagnostics) String() string {
+	var build strings.Builder
+	for _, d := range ds {
+		build.WriteString(d.String() + "\n")
+	}
+	return build.String()
+}
+
+// AnalyzeWithRecovery 分析并从错误中恢复 一直分析到输入结束
+// 文法不是 LL(1) 的或者规则有误时返回 error
+func AnalyzeWithRecovery(raw []*lexer.Token, rules string, start string, mode string) ([]*Production, Diagnostics, error) {
+	g, _, err := loadRules(rules, start)
+	if err != nil {
+		return nil, nil, err
+	}
+	firstSet := first.GetFirstSet(g)
+	followSet := follow.GetFollowSet(g, start, firstSet)
+	table, conflicts := analysisTable.BuildAnalyzeTable(firstSet, followSet, g, start)
+	if !conflicts.IsLL1() {
+		return nil, nil, fmt.Errorf("grammar is not LL(1):\n%s", conflicts.String())
+	}
+	grm := NewGrammar(raw, bytes.NewBufferString(start), EndToken, table)
+	grm.Matcher = g.Matcher()
+	prod, diagnostics := grm.Recover(followSet, mode)
+	return prod, diagnostics, nil
+}
+
+// Recover 带有错误恢复的分析 followSet 用作同步集
+// 返回的产生式序列中 恢复时的动作以 Type 为 scan pop insert delete 的条目记录
+func (g *Grammar) Recover(followSet follow.FollowSet, mode string) (res []*Production, diagnostics Diagnostics) {
+	report := func(top string, action string, symbol string) {
+		g.record(action, nil)
+		found := g.tokens.Front().(*lexer.Token)
+		diagnostics = append(diagnostics, &Diagnostic{
+			Row:      found.Row,
+			Column:   found.Column,
+			Found:    found,
+			Expected: g.expected(top),
+			Action:   action,
+			Symbol:   symbol,
+		})
+		res = append(res, &Production{Type: action, Target: symbol})
+	}
+	skip := func() {
+		g.tokens.Pop()
+	}
+	for {
+		current := g.tokens.Front().(*lexer.Token)
+		top := g.top()
+		if top == EndToken {
+			if current.Typ == lexer.END {
+				g.record(Accept, nil)
+				res = append(res, &Production{Type: "kill", Target: EndToken})
+				return
+			}
+			// 栈已经空了 剩下的输入都是多余的
+			report(top, Scan, current.Value)
+			skip()
+			continue
+		}
+		if top == "&" {
+			g.record(Match, nil)
+			g.pop()
+			res = append(res, &Production{Type: "kill", Target: top})
+			continue
+		}
+		if util2.IsTerminal(top[0]) {
+			if g.Matcher.Match(top, current) {
+				g.record(Match, nil)
+				g.pop()
+				res = append(res, &Production{Type: "kill", Target: current.Value})
+				skip()
+				continue
+			}
+			if mode == PhraseLevel && current.Typ != lexer.END && g.Matcher.Match(top, g.lookahead(1)) {
+				report(top, Delete, current.Value)
+				skip()
+				continue
+			}
+			report(top, Insert, top)
+			g.pop()
+			continue
+		}
+		symbol := g.Matcher.TerminalOf(current)
+		if proc := g.table.Get(top, symbol); proc != nil {
+			g.record(Derive, proc)
+			g.pop()
+			g.push(proc.Right)
+			res = append(res, &Production{
+				Type:   "Continue",
+				Origin: proc.Left,
+				Next:   proc.Right,
+			})
+			continue
+		}
+		if mode == PhraseLevel && current.Typ != lexer.END {
+			if next := g.lookahead(1); g.table.Get(top, g.Matcher.TerminalOf(next)) != nil {
+				report(top, Delete, current.Value)
+				skip()
+				continue
+			}
+		}
+		// 空白格子 FOLLOW 集里的符号或者输入结束时弹出 否则跳过
+		// 栈中只剩开始符号时不弹出 否则剩下的输入只能全部跳过
+		if followSet.Contains(top, symbol) && len(g.stack) > 2 || current.Typ == lexer.END {
+			report(top, Pop, top)
+			g.pop()
+			continue
+		}
+		report(top, Scan, current.Value)
+		skip()
+	}
+}
+
+// expected 栈顶为 top 时可以接受的终结符
+func (g *Grammar) expected(top string) []string {
+	if top == EndToken || util2.IsTerminal(top[0]) {
+		return []string{top}
+	}
+	var res []string
+	for _, symbol := range g.table.Terminals {
+		if g.table.Get(top, symbol) != nil {
+			res = append(res, symbol)
+		}
+	}
+	return res
+}
+
+// lookahead 当前词法单元之后第 n 个词法单元 超出时返回结束符
+func (g *Grammar) lookahead(n int) *lexer.Token {
+	e := g.tokens.FrontRaw()
+	for i := 0; i < n && e != nil; i++ {
+		e = e.Next()
+	}
+	if e == nil {
+		return &lexer.Token{Typ: lexer.END, Value: EndToken}
+	}
+	return e.Value.(*lexer.Token)
+}
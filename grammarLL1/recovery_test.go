@@ -0,0 +1,68 @@
+package grammarLL1
+
+import (
+	"fmt"
+	"github.com/esonhugh/compiler/lexer"
+	"strings"
+	"testing"
+)
+
+const expressionLL1 = "E->TA\nA->+TA|&\nT->FB\nB->*FB|&\nF->(E)|i"
+
+// recoverActions 每个错误记为 列:动作 符号 测试的句子都只有一行
+func recoverActions(t *testing.T, code string, mode string) (string, Diagnostics) {
+	prod, diagnostics, err := AnalyzeWithRecovery(lexer.Analyse(code), expressionLL1, "E", mode)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last := prod[len(prod)-1]; last.Type != "kill" || last.Target != EndToken {
+		t.Fatalf("%s: parsing should continue to the end of input", code)
+	}
+	var actions []string
+	for _, d := range diagnostics {
+		actions = append(actions, fmt.Sprintf("%d:%s %s", d.Column, d.Action, d.Symbol))
+	}
+	t.Log(diagnostics.String())
+	return strings.Join(actions, ", "), diagnostics
+}
+
+func TestPanicMode(t *testing.T) {
+	cases := map[string]string{
+		"a+b*c":         "",
+		"a+*b":          "3:scan *",
+		"(a+b":          "5:insert )",
+		"a b":           "3:scan b",
+		"a+b)":          "4:scan )",
+		")a+b":          "1:scan )",
+		"a + + b + * c": "5:pop T, 11:scan *",
+	}
+	for code, want := range cases {
+		if got, _ := recoverActions(t, code, PanicMode); got != want {
+			t.Errorf("%s: got %q, want %q", code, got, want)
+		}
+	}
+}
+
+func TestPhraseLevel(t *testing.T) {
+	cases := map[string]string{
+		"a+*b":  "3:delete *",
+		"(a+b":  "5:insert )",
+		"a+b)":  "4:scan )",
+		"(a b)": "4:delete b",
+	}
+	for code, want := range cases {
+		if got, _ := recoverActions(t, code, PhraseLevel); got != want {
+			t.Errorf("%s: got %q, want %q", code, got, want)
+		}
+	}
+}
+
+func TestDiagnosticExpected(t *testing.T) {
+	_, diagnostics := recoverActions(t, "a + + b", PanicMode)
+	if len(diagnostics) != 1 {
+		t.Fatalf("expected one error, got\n%s", diagnostics.String())
+	}
+	if want := "line 1 col 5: expected ( or i, found '+' (pop T)"; diagnostics[0].String() != want {
+		t.Errorf("got %s, want %s", diagnostics[0].String(), want)
+	}
+}
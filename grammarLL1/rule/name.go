@@ -0,0 +1,96 @@
+package rule
+
+import (
+	"errors"
+	"github.com/esonhugh/compiler/grammarLL1/util"
+	"github.com/esonhugh/compiler/util/transfer"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// addName 处理 %name G E' 声明 非终结符 G 在输出中显示为 E'
+// 文法变换新建的非终结符也记录在 Names 中
+func (r *Rule) addName(fields []string) error {
+	if len(fields) != 3 || len(fields[1]) != 1 || util.IsTerminal(fields[1][0]) {
+		return errors.New("invalid %name declaration")
+	}
+	r.Names[fields[1]] = fields[2]
+	return nil
+}
+
+// longName 多个字符的非终结符 <Expr> 名字至少两个字符 由字母 数字 _ 和 ' 组成
+var longName = regexp.MustCompile(`<([A-Za-z_][A-Za-z0-9_']+)>`)
+
+// reservedLetters 规则中直接使用的大写字母 不能分配给 <Expr> 这类非终结符
+func reservedLetters(lines []string) map[byte]struct{} {
+	res := make(map[byte]struct{})
+	for _, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), "%") {
+			continue
+		}
+		line = longName.ReplaceAllString(line, "")
+		for i := 0; i < len(line); i++ {
+			if line[i] >= 'A' && line[i] <= 'Z' {
+				res[line[i]] = struct{}{}
+			}
+		}
+	}
+	return res
+}
+
+// expandNames 把一行规则中的 <Expr> 换成对应的字母
+// 已经有这个显示名的非终结符直接使用 否则分配一个空闲的大写字母
+func (r *Rule) expandNames(line string, reserved map[byte]struct{}) (string, error) {
+	var err error
+	res := longName.ReplaceAllStringFunc(line, func(m string) string {
+		sym := r.Symbol(m)
+		if err != nil || sym != m {
+			return sym
+		}
+		if sym, err = r.newNonterminal(reserved); err != nil {
+			return m
+		}
+		r.Names[sym] = m[1 : len(m)-1]
+		return sym
+	})
+	return res, err
+}
+
+// Symbol 符号的内部表示 <Expr> 换成显示名为 Expr 的非终结符 其余符号保持原样
+func (r *Rule) Symbol(s string) string {
+	m := longName.FindStringSubmatch(s)
+	if m == nil || m[0] != s {
+		return s
+	}
+	var symbols []string
+	for sym, name := range r.Names {
+		if name == m[1] {
+			symbols = append(symbols, sym)
+		}
+	}
+	if len(symbols) == 0 {
+		return s
+	}
+	sort.Strings(symbols)
+	return symbols[0]
+}
+
+// Name 符号串的显示形式 有显示名的非终结符替换为显示名 & 换为 ε 其余符号保持原样
+func (r *Rule) Name(str string) string {
+	return transfer.TransferWith(str, r.Names)
+}
+
+// nameDeclarations 按符号排序输出 %name 声明
+func (r *Rule) nameDeclarations() string {
+	var symbols []string
+	for sym := range r.Names {
+		symbols = append(symbols, sym)
+	}
+	sort.Strings(symbols)
+	var build strings.Builder
+	for _, sym := range symbols {
+		build.WriteString("%name " + sym + " " + r.Names[sym] + "\n")
+	}
+	return build.String()
+}
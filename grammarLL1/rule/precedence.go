@@ -0,0 +1,88 @@
+package rule
+
+import (
+	"errors"
+	"fmt"
+	"github.com/esonhugh/compiler/grammarLL1/util"
+	"sort"
+	"strings"
+)
+
+// 结合性
+const (
+	Left     = "left"
+	Right    = "right"
+	NonAssoc = "nonassoc"
+)
+
+// Precedence 终结符的优先级和结合性 Level 越大优先级越高
+type Precedence struct {
+	Level int
+	Assoc string
+}
+
+// addDeclaration 处理 %left + - 这样的声明 与 yacc 相同 后声明的一行优先级更高
+// %token 声明交给 addToken %name 声明交给 addName
+func (r *Rule) addDeclaration(line string) error {
+	fields := strings.Fields(line)
+	switch fields[0] {
+	case "%token":
+		return r.addToken(fields)
+	case "%name":
+		return r.addName(fields)
+	}
+	assoc := strings.TrimPrefix(fields[0], "%")
+	if assoc != Left && assoc != Right && assoc != NonAssoc {
+		return errors.New("unknown declaration " + fields[0])
+	}
+	level := 1
+	for _, p := range r.Precedences {
+		if p.Level >= level {
+			level = p.Level + 1
+		}
+	}
+	for _, sym := range fields[1:] {
+		if len(sym) != 1 {
+			return errors.New("invalid precedence symbol " + sym)
+		}
+		r.Precedences[sym] = Precedence{Level: level, Assoc: assoc}
+	}
+	return nil
+}
+
+// PrecedenceOf 产生式的优先级
+// 有 %prec 时使用指定符号的优先级 否则使用右部最后一个终结符的优先级
+func (r *Rule) PrecedenceOf(f Formula) (Precedence, bool) {
+	if sym, ok := r.Prec[f]; ok {
+		p, ok := r.Precedences[sym]
+		return p, ok
+	}
+	for i := len(f.Right) - 1; i >= 0; i-- {
+		if f.Right[i] != '&' && util.IsTerminal(f.Right[i]) {
+			p, ok := r.Precedences[string(f.Right[i])]
+			return p, ok
+		}
+	}
+	return Precedence{}, false
+}
+
+// declarations 按优先级从低到高输出声明
+func (r *Rule) declarations() string {
+	levels := make(map[int][]string)
+	assoc := make(map[int]string)
+	for sym, p := range r.Precedences {
+		levels[p.Level] = append(levels[p.Level], sym)
+		assoc[p.Level] = p.Assoc
+	}
+	var order []int
+	for level := range levels {
+		order = append(order, level)
+	}
+	sort.Ints(order)
+	var build strings.Builder
+	for _, level := range order {
+		sort.Strings(levels[level])
+		build.WriteString(fmt.Sprintf("%%%s %s\n", assoc[level], strings.Join(levels[level], " ")))
+	}
+	return build.String()
+}
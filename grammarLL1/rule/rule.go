@@ -6,14 +6,26 @@ package rule
 
 import (
 	"errors"
+	"sort"
 	"strings"
 )
 
 // 规则合集
 type Rule struct {
 	Rules map[string][]string
+	Order []string // 非终结符的声明顺序
+
+	Precedences map[string]Precedence // 终结符的优先级和结合性 由 %left %right %nonassoc 声明
+	Prec        map[Formula]string    // 产生式用 %prec 指定的优先级符号
+
+	Tokens map[string]*TokenPattern // 终结符能匹配的词法单元 由 %token 声明
+
+	Names map[string]string // 非终结符的显示名 由 %name 声明或者由文法变换生成 例如 G => E'
 }
 
+// EndToken 句子结束符号
+const EndToken = "#"
+
 // 表达式 分为左右两边
 type Formula struct {
 	Left  string
@@ -22,28 +34,192 @@ type Formula struct {
 
 // 新建一个空的 规则
 func NewRules() *Rule {
-	return &Rule{Rules: make(map[string][]string)}
+	return &Rule{
+		Rules:       make(map[string][]string),
+		Precedences: make(map[string]Precedence),
+		Prec:        make(map[Formula]string),
+		Tokens:      make(map[string]*TokenPattern),
+		Names:       make(map[string]string),
+	}
+}
+
+// Parse 读入规则 格式与 AddRules 相同
+func Parse(s string) (*Rule, error) {
+	r := NewRules()
+	if err := r.AddRules(s); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// MustParse 读入规则 出错时 panic 用于测试和内置的文法
+func MustParse(s string) *Rule {
+	r, err := Parse(s)
+	if err != nil {
+		panic("rule: " + err.Error() + ": " + s)
+	}
+	return r
 }
 
 // 添加规则到规则集和中
+// 多个字符的非终结符写作 <Expr> 读入时换成空闲的大写字母 原名作为显示名记录在 Names 中
 func (r *Rule) AddRules(s string) error {
 	lineRule := strings.Split(s, "\n")
+	reserved := reservedLetters(lineRule)
 	for _, t := range lineRule {
 		if strings.TrimSpace(t) == "" {
 			continue
 		}
+		if strings.HasPrefix(strings.TrimSpace(t), "%") {
+			if err := r.addDeclaration(strings.TrimSpace(t)); err != nil {
+				return err
+			}
+			continue
+		}
+		t, err := r.expandNames(t, reserved)
+		if err != nil {
+			return err
+		}
 		c := strings.Split(t, "->")
 		if len(c) != 2 || len(c[0]) != 1 {
 			return errors.New("invalid arg")
 		}
 		right := strings.Split(strings.ReplaceAll(c[1], " ", ""), "|")
+		if _, ok := r.Rules[c[0]]; !ok {
+			r.Order = append(r.Order, c[0])
+		}
 		for i := range right {
+			// A->-E %prec u
+			if p := strings.Index(right[i], "%prec"); p >= 0 {
+				sym := right[i][p+len("%prec"):]
+				right[i] = right[i][:p]
+				if len(sym) != 1 {
+					return errors.New("invalid %prec")
+				}
+				r.Prec[Formula{Left: c[0], Right: right[i]}] = sym
+			}
 			r.Rules[c[0]] = append(r.Rules[c[0]], right[i])
 		}
 	}
 	return nil
 }
 
+// AddFormula 添加一条产生式 左部第一次出现时记录声明顺序
+func (r *Rule) AddFormula(left, right string) {
+	if _, ok := r.Rules[left]; !ok {
+		r.Order = append(r.Order, left)
+	}
+	r.Rules[left] = append(r.Rules[left], right)
+}
+
+// Nonterminals 按声明顺序返回全部非终结符
+// 没有经过 AddRules 登记顺序的非终结符按字母序排在最后
+func (r *Rule) Nonterminals() []string {
+	res := make([]string, 0, len(r.Rules))
+	seen := make(map[string]struct{})
+	for _, key := range r.Order {
+		if _, ok := r.Rules[key]; !ok {
+			continue
+		}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		res = append(res, key)
+	}
+	var rest []string
+	for key := range r.Rules {
+		if _, ok := seen[key]; !ok {
+			rest = append(rest, key)
+		}
+	}
+	sort.Strings(rest)
+	return append(res, rest...)
+}
+
+// Formulas 按声明顺序返回全部产生式
+func (r *Rule) Formulas() []*Formula {
+	var res []*Formula
+	for _, left := range r.Nonterminals() {
+		for _, right := range r.Rules[left] {
+			res = append(res, &Formula{Left: left, Right: right})
+		}
+	}
+	return res
+}
+
+// Clone 深拷贝一份规则 变换文法时不修改原始规则
+func (r *Rule) Clone() *Rule {
+	n := NewRules()
+	for _, left := range r.Nonterminals() {
+		n.Order = append(n.Order, left)
+		n.Rules[left] = append([]string(nil), r.Rules[left]...)
+	}
+	for key, value := range r.Precedences {
+		n.Precedences[key] = value
+	}
+	for key, value := range r.Prec {
+		n.Prec[key] = value
+	}
+	for key, value := range r.Tokens {
+		n.Tokens[key] = value
+	}
+	for key, value := range r.Names {
+		n.Names[key] = value
+	}
+	return n
+}
+
+// NewNonterminal 分配一个文法中尚未使用的大写字母作为新的非终结符
+// 由于是单个字符匹配 E' 这类新符号只能用空闲字母表示
+func (r *Rule) NewNonterminal() (string, error) {
+	return r.newNonterminal(nil)
+}
+
+// newNonterminal 分配空闲的大写字母 reserved 中的字母和已经有显示名的字母也不能使用
+func (r *Rule) newNonterminal(reserved map[byte]struct{}) (string, error) {
+	used := make(map[byte]struct{})
+	for c := range reserved {
+		used[c] = struct{}{}
+	}
+	for sym := range r.Names {
+		used[sym[0]] = struct{}{}
+	}
+	for left, rights := range r.Rules {
+		used[left[0]] = struct{}{}
+		for _, right := range rights {
+			for i := 0; i < len(right); i++ {
+				used[right[i]] = struct{}{}
+			}
+		}
+	}
+	for c := byte('A'); c <= 'Z'; c++ {
+		if _, ok := used[c]; !ok {
+			return string(c), nil
+		}
+	}
+	return "", errors.New("no free nonterminal")
+}
+
+// String 按声明顺序输出规则 格式与 AddRules 的输入相同
+func (r *Rule) String() string {
+	var build strings.Builder
+	build.WriteString(r.tokenDeclarations())
+	build.WriteString(r.nameDeclarations())
+	build.WriteString(r.declarations())
+	for _, left := range r.Nonterminals() {
+		var rights []string
+		for _, right := range r.Rules[left] {
+			if sym, ok := r.Prec[Formula{Left: left, Right: right}]; ok {
+				right += " %prec " + sym
+			}
+			rights = append(rights, right)
+		}
+		build.WriteString(left + "->" + strings.Join(rights, "|") + "\n")
+	}
+	return build.String()
+}
+
 // 是否有空产生式
 func (r *Rule) HaveEmptySet(first string) bool {
 	for _, value := range r.Rules[first] {
@@ -0,0 +1,88 @@
+package rule
+
+import (
+	"github.com/esonhugh/compiler/grammarLL1/util"
+	"github.com/esonhugh/compiler/util/transfer"
+	"sort"
+)
+
+// Symbols 文法符号的有序编号 所有集合和表格都按这个顺序输出
+// 非终结符按声明顺序 终结符按字典序 最后是 # ε 排在全部终结符之后
+type Symbols struct {
+	Nonterminals []string
+	Terminals    []string
+	Names        map[string]string // Names 文法的显示名
+	index        map[string]int
+}
+
+// Symbols 文法的符号编号
+func (r *Rule) Symbols() *Symbols {
+	s := &Symbols{Nonterminals: r.Nonterminals(), Names: r.Names, index: make(map[string]int)}
+	seen := make(map[string]bool)
+	for _, f := range r.Formulas() {
+		for i := 0; i < len(f.Right); i++ {
+			c := f.Right[i : i+1]
+			if c != "&" && c != EndToken && util.IsTerminal(f.Right[i]) && !seen[c] {
+				seen[c] = true
+				s.Terminals = append(s.Terminals, c)
+			}
+		}
+	}
+	sort.Strings(s.Terminals)
+	s.Terminals = append(s.Terminals, EndToken)
+	for i, n := range s.Nonterminals {
+		s.index[n] = i
+	}
+	for i, t := range s.Terminals {
+		s.index[t] = len(s.Nonterminals) + i
+	}
+	s.index["&"] = len(s.Nonterminals) + len(s.Terminals)
+	return s
+}
+
+// Index 符号的编号 非终结符在前 终结符在后 不在文法中的符号返回 -1
+func (s *Symbols) Index(symbol string) int {
+	if i, ok := s.index[symbol]; ok {
+		return i
+	}
+	return -1
+}
+
+// Name 符号串的显示形式 与 Rule.Name 相同
+func (s *Symbols) Name(str string) string {
+	return transfer.TransferWith(str, s.Names)
+}
+
+// Less 符号 a 是否排在 b 之前 不在文法中的符号按字典序排在最后
+func (s *Symbols) Less(a, b string) bool {
+	i, j := s.Index(a), s.Index(b)
+	switch {
+	case i >= 0 && j >= 0:
+		return i < j
+	case i >= 0 || j >= 0:
+		return i >= 0
+	}
+	return a < b
+}
+
+// Sort 按编号顺序返回集合中的符号
+func (s *Symbols) Sort(set map[string]struct{}) []string {
+	res := make([]string, 0, len(set))
+	for key := range set {
+		res = append(res, key)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return s.Less(res[i], res[j])
+	})
+	return res
+}
+
+// LessString 逐个符号比较两个符号串 前缀排在前面 用于 LL(k) 的向前看串
+func (s *Symbols) LessString(a, b string) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return s.Less(a[i:i+1], b[i:i+1])
+		}
+	}
+	return len(a) < len(b)
+}
@@ -0,0 +1,183 @@
+package rule
+
+import (
+	"errors"
+	"github.com/esonhugh/compiler/grammarLL1/util"
+	"github.com/esonhugh/compiler/lexer"
+	"sort"
+	"strings"
+)
+
+// TokenPattern 终结符能匹配的词法单元 由 %token 声明
+//
+//	%token i VARIABLE|INTEGER|FLOAT   i 匹配变量和数字
+//	%token n INTEGER                  n 只匹配整数
+//	%token a "&&"                     a 匹配值为 && 的词法单元
+//	%token p OPERATOR "+"             p 匹配值为 + 的运算符
+type TokenPattern struct {
+	Types []lexer.TokenType
+	Value string // 为空时不限制值
+}
+
+// tokenTypes %token 中可以使用的类型名
+var tokenTypes = map[string]lexer.TokenType{
+	"KEYWORD":  lexer.KEYWORD,
+	"TYPE":     lexer.TYPE,
+	"VARIABLE": lexer.VARIABLE,
+	"OPERATOR": lexer.OPERATOR,
+	"BRACKET":  lexer.BRACKET,
+	"STRING":   lexer.STRING,
+	"FLOAT":    lexer.FLOAT,
+	"BOOLEAN":  lexer.BOOLEAN,
+	"INTEGER":  lexer.INTEGER,
+}
+
+// DefaultIdentifier 没有声明时 i 匹配变量和常量 与递归下降分析器的 IsValue 相同
+var DefaultIdentifier = &TokenPattern{Types: []lexer.TokenType{
+	lexer.VARIABLE, lexer.INTEGER, lexer.FLOAT, lexer.BOOLEAN, lexer.STRING,
+}}
+
+// addToken 处理 %token 声明
+func (r *Rule) addToken(fields []string) error {
+	if len(fields) < 3 || len(fields[1]) != 1 {
+		return errors.New("invalid %token declaration")
+	}
+	p := &TokenPattern{}
+	for _, field := range fields[2:] {
+		if len(field) >= 2 && (field[0] == '"' || field[0] == '\'') && field[len(field)-1] == field[0] {
+			p.Value = field[1 : len(field)-1]
+			continue
+		}
+		for _, name := range strings.Split(field, "|") {
+			typ, ok := tokenTypes[strings.ToUpper(name)]
+			if !ok {
+				return errors.New("unknown token type " + name)
+			}
+			p.Types = append(p.Types, typ)
+		}
+	}
+	r.Tokens[fields[1]] = p
+	return nil
+}
+
+// match 词法单元是否符合 p
+func (p *TokenPattern) match(t *lexer.Token) bool {
+	if p.Value != "" && p.Value != t.Value {
+		return false
+	}
+	if len(p.Types) == 0 {
+		return true
+	}
+	for _, typ := range p.Types {
+		if typ == t.Typ {
+			return true
+		}
+	}
+	return false
+}
+
+// String 声明中的形式 VARIABLE|INTEGER "+"
+func (p *TokenPattern) String() string {
+	var names []string
+	for _, typ := range p.Types {
+		for name, t := range tokenTypes {
+			if t == typ {
+				names = append(names, name)
+			}
+		}
+	}
+	res := strings.Join(names, "|")
+	if p.Value != "" {
+		if res != "" {
+			res += " "
+		}
+		res += `"` + p.Value + `"`
+	}
+	return res
+}
+
+// tokenDeclarations 按终结符排序输出 %token 声明
+func (r *Rule) tokenDeclarations() string {
+	var terminals []string
+	for terminal := range r.Tokens {
+		terminals = append(terminals, terminal)
+	}
+	sort.Strings(terminals)
+	var build strings.Builder
+	for _, terminal := range terminals {
+		build.WriteString("%token " + terminal + " " + r.Tokens[terminal].String() + "\n")
+	}
+	return build.String()
+}
+
+// Matcher 把词法单元对应到文法的终结符 所有的分析器共用
+type Matcher struct {
+	terminals []string
+	patterns  map[string]*TokenPattern
+}
+
+// NewMatcher 根据终结符和声明创建 Matcher
+// 没有声明的终结符按值匹配 i 没有声明时使用 DefaultIdentifier
+func NewMatcher(terminals []string, patterns map[string]*TokenPattern) *Matcher {
+	m := &Matcher{patterns: make(map[string]*TokenPattern)}
+	seen := make(map[string]bool)
+	for _, terminal := range terminals {
+		if terminal != EndToken && !seen[terminal] {
+			seen[terminal] = true
+			m.terminals = append(m.terminals, terminal)
+		}
+	}
+	sort.Strings(m.terminals)
+	for terminal, p := range patterns {
+		m.patterns[terminal] = p
+	}
+	if _, ok := m.patterns["i"]; !ok {
+		m.patterns["i"] = DefaultIdentifier
+	}
+	return m
+}
+
+// Matcher 规则的 Matcher 终结符为产生式右部出现的全部终结符
+func (r *Rule) Matcher() *Matcher {
+	var terminals []string
+	for _, f := range r.Formulas() {
+		for i := 0; i < len(f.Right); i++ {
+			if f.Right[i] != '&' && util.IsTerminal(f.Right[i]) {
+				terminals = append(terminals, string(f.Right[i]))
+			}
+		}
+	}
+	return NewMatcher(terminals, r.Tokens)
+}
+
+// TerminalOf 词法单元对应的终结符 结束符对应 # 没有对应的终结符时返回空串
+// 先看声明了值的终结符 再看按值匹配的终结符 然后看只声明了类型的终结符 最后才是默认的 i
+func (m *Matcher) TerminalOf(t *lexer.Token) string {
+	if t.Typ == lexer.END {
+		return EndToken
+	}
+	for _, terminal := range m.terminals {
+		if p, ok := m.patterns[terminal]; ok && p.Value != "" && p.match(t) {
+			return terminal
+		}
+	}
+	for _, terminal := range m.terminals {
+		if _, ok := m.patterns[terminal]; !ok && terminal == t.Value && t.Typ != lexer.STRING {
+			return terminal
+		}
+	}
+	for _, terminal := range m.terminals {
+		if p, ok := m.patterns[terminal]; ok && p != DefaultIdentifier && p.Value == "" && p.match(t) {
+			return terminal
+		}
+	}
+	if p := m.patterns["i"]; p == DefaultIdentifier && p.match(t) {
+		return "i"
+	}
+	return ""
+}
+
+// Match 词法单元是否匹配终结符
+func (m *Matcher) Match(terminal string, t *lexer.Token) bool {
+	return m.TerminalOf(t) == terminal
+}
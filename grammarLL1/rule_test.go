@@ -1,11 +1,13 @@
 package grammarLL1
 
 import (
+	"fmt"
 	"github.com/esonhugh/compiler/grammarLL1/analysisTable"
 	"github.com/esonhugh/compiler/grammarLL1/first"
 	"github.com/esonhugh/compiler/grammarLL1/follow"
 	"github.com/esonhugh/compiler/grammarLL1/rule"
-	"fmt"
+	"github.com/esonhugh/compiler/lexer"
+	"strings"
 	"testing"
 )
 
@@ -21,3 +23,181 @@ func TestNewRule(t *testing.T) {
 	res := table.String()
 	fmt.Println(res)
 }
+
+func TestIsLL1(t *testing.T) {
+	ok, conflicts, err := IsLL1("E->TG\nG->ATG|&\nT->FS\nS->MFS|&\nF->(E)|i\nA->+|-\nM->*|/", "E")
+	if err != nil || !ok || len(conflicts) != 0 {
+		t.Fatalf("expression grammar should be LL(1):\n%s", conflicts.String())
+	}
+}
+
+func TestFirstFirstConflict(t *testing.T) {
+	ok, conflicts, err := IsLL1("E->E+T|T\nT->i", "E")
+	if err != nil || ok {
+		t.Fatal("left recursive grammar should not be LL(1)")
+	}
+	t.Log(conflicts.String())
+	if conflicts[0].Kind != analysisTable.FirstFirst || conflicts[0].Left != "E" || conflicts[0].Lookahead != "i" {
+		t.Fatalf("unexpected conflict %s", conflicts[0].String())
+	}
+}
+
+func TestFirstFollowConflict(t *testing.T) {
+	// 悬挂 else
+	ok, conflicts, err := IsLL1("C->iEtCP|a\nP->eC|&\nE->b", "C")
+	if err != nil || ok {
+		t.Fatal("dangling else grammar should not be LL(1)")
+	}
+	t.Log(conflicts.String())
+	if len(conflicts) != 1 || conflicts[0].Kind != analysisTable.FirstFollow || conflicts[0].Lookahead != "e" {
+		t.Fatalf("unexpected conflicts %s", conflicts.String())
+	}
+}
+
+func TestConflictNames(t *testing.T) {
+	// 没有 %name 声明时 S 和 G 按原样输出
+	_, conflicts, err := IsLL1("S->SG|G\nG->a", "S")
+	if err != nil || len(conflicts) == 0 {
+		t.Fatal("left recursive grammar should not be LL(1)")
+	}
+	if report := conflicts.String(); !strings.Contains(report, "M[S, a]") || !strings.Contains(report, "S->SG") || strings.Contains(report, "'") {
+		t.Fatalf("got\n%s", report)
+	}
+	_, conflicts, _ = IsLL1("%name S T'\nS->SG|G\nG->a", "S")
+	if report := conflicts.String(); !strings.Contains(report, "M[T', a]") {
+		t.Fatalf("got\n%s", report)
+	}
+}
+
+func TestMultiCharacterNonterminals(t *testing.T) {
+	// A 已经在规则中使用 <Expr> 和 <Rest> 分配到 B 和 C
+	g := rule.MustParse("<Expr>->A<Rest>\nA->i\n<Rest>->+A<Rest>|&")
+	want := "%name B Expr\n%name C Rest\nB->AC\nA->i\nC->+AC|&\n"
+	if g.String() != want {
+		t.Fatalf("got\n%s", g.String())
+	}
+	if g.Symbol("<Rest>") != "C" || g.Symbol("<Term>") != "<Term>" || g.Name("AC") != "ARest" {
+		t.Fatal("unexpected symbols")
+	}
+	// 单个字符的 <E> 和比较运算符仍然是终结符
+	g = rule.MustParse("E->E<E>|i")
+	if g.Rules["E"][0] != "E<E>" || len(g.Names) != 0 {
+		t.Fatalf("got\n%s", g.String())
+	}
+}
+
+func TestPrecedenceDeclarations(t *testing.T) {
+	g := rule.NewRules()
+	if err := g.AddRules("%left + -\n%left *\n%right u\nE->E+E|E-E|E*E|-E %prec u|i"); err != nil {
+		t.Fatal(err)
+	}
+	if g.Precedences["*"].Level <= g.Precedences["+"].Level || g.Precedences["u"].Assoc != rule.Right {
+		t.Fatalf("unexpected precedences %v", g.Precedences)
+	}
+	p, ok := g.PrecedenceOf(rule.Formula{Left: "E", Right: "-E"})
+	if !ok || p != g.Precedences["u"] {
+		t.Fatal("prec declaration should override the last terminal")
+	}
+	want := "%left + -\n%left *\n%right u\nE->E+E|E-E|E*E|-E %prec u|i\n"
+	if g.String() != want {
+		t.Fatalf("got\n%s", g.String())
+	}
+	if g.AddRules("%middle +") == nil {
+		t.Fatal("unknown declaration should be rejected")
+	}
+}
+
+func TestTokenDeclarations(t *testing.T) {
+	g := rule.NewRules()
+	err := g.AddRules("%token n INTEGER|FLOAT\n%token a \"&&\"\n%token p OPERATOR \"+\"\nE->EpT|EaT|T\nT->n|i|(E)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := g.Matcher()
+	cases := map[string]string{
+		"12":  "n",
+		"1.5": "n",
+		"x":   "i",
+		"&&":  "a",
+		"+":   "p",
+		"(":   "(",
+		"-":   "",
+	}
+	for code, want := range cases {
+		tokens := lexer.Analyse(code)
+		if got := m.TerminalOf(tokens[0]); got != want {
+			t.Errorf("%s: got %q, want %q", code, got, want)
+		}
+	}
+	if !m.Match(rule.EndToken, &lexer.Token{Typ: lexer.END}) {
+		t.Error("end of input should match #")
+	}
+	want := "%token a \"&&\"\n%token n INTEGER|FLOAT\n%token p OPERATOR \"+\"\nE->EpT|EaT|T\nT->n|i|(E)\n"
+	if g.String() != want {
+		t.Fatalf("got\n%s", g.String())
+	}
+	if g.AddRules("%token x NUMBER") == nil {
+		t.Fatal("unknown token type should be rejected")
+	}
+}
+
+func TestTokenDeclarationsAnalyze(t *testing.T) {
+	rules := "%token n INTEGER\n%token i VARIABLE\nE->TG\nG->+TG|&\nT->n|i"
+	if res, err := Analyze(lexer.Analyse("a+1+b"), rules, "E"); err != nil || !res.Accepted {
+		t.Fatal("a+1+b should be accepted")
+	}
+	if res, err := Analyze(lexer.Analyse("a+1.5"), rules, "E"); err != nil || res.Accepted {
+		t.Fatal("1.5 is not an INTEGER")
+	}
+	// 没有声明时 i 与递归下降分析器一样匹配变量和常量
+	if res, err := Analyze(lexer.Analyse("i+1"), "E->TG\nG->+TG|&\nT->i", "E"); err != nil || !res.Accepted {
+		t.Fatal("i+1 should be accepted")
+	}
+}
+
+func TestStripUselessIsOptIn(t *testing.T) {
+	// C 不可达 构造分析表时保留 只有 StripUseless 会删除
+	rules := "E->i\nC->c"
+	g, _, _, err := BuildTable(rules, "E")
+	if err != nil || len(g.Rules["C"]) == 0 {
+		t.Fatal("BuildTable should keep the grammar unchanged")
+	}
+	stripped, report, err := StripUseless(rules, "E")
+	if err != nil || stripped != "E->i\n" || len(report.Unreachable) == 0 {
+		t.Fatalf("got %q %v %v", stripped, report, err)
+	}
+}
+
+func TestNameDeclarations(t *testing.T) {
+	g := rule.MustParse("%name G E'\nE->TG\nG->+TG|&\nT->i")
+	if got := g.Name("TG|&"); got != "TE'|ε" {
+		t.Fatalf("got %s", got)
+	}
+	// 没有声明的 S 保持原样
+	if got := rule.MustParse("S->iEtS|a\nE->b").Name("S->iEtS"); got != "S->iEtS" {
+		t.Fatalf("got %s", got)
+	}
+	if want := "%name G E'\nE->TG\nG->+TG|&\nT->i\n"; g.String() != want {
+		t.Fatalf("got\n%s", g.String())
+	}
+	if _, err := rule.Parse("%name g E'"); err == nil {
+		t.Fatal("only nonterminals can be renamed")
+	}
+}
+
+func TestAnalyzeResult(t *testing.T) {
+	res, err := Analyze(lexer.Analyse("a+b"), "E->TG\nG->+TG|&\nT->i", "E")
+	if err != nil || !res.Accepted || len(res.Trace) == 0 {
+		t.Fatalf("a+b should be accepted: %v", err)
+	}
+	if res.First.String() != "FIRST(E) = { i }\nFIRST(G) = { + ε }\nFIRST(T) = { i }\n" || res.Table.Get("G", "#").Right != "&" {
+		t.Fatalf("unexpected result\n%s%s", res.First.String(), res.Table.String())
+	}
+	res, err = Analyze(lexer.Analyse("a"), "E->E+T|T\nT->i", "E")
+	if err == nil || res.Conflicts.IsLL1() || res.Trace != nil {
+		t.Fatal("left recursive grammar should be rejected before analysing the input")
+	}
+	if res, err = Analyze(lexer.Analyse("a"), "E->X", "E"); err == nil || res.Report.IsUsable() {
+		t.Fatal("undefined nonterminal should be reported")
+	}
+}
@@ -0,0 +1,92 @@
+package grammarLL1
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"github.com/esonhugh/compiler/util/transfer"
+	"github.com/liushuochen/gotable"
+	"strconv"
+	"strings"
+)
+
+// Trace 全部分析步骤 可以输出为终端表格 CSV Markdown 和 JSON
+type Trace []*TraceStep
+
+// traceColumns 输出的列
+var traceColumns = []string{"步骤", "分析栈", "剩余输入", "动作", "产生式"}
+
+// rows 每一步对应的一行
+func (t Trace) rows() [][]string {
+	var rows [][]string
+	for i, step := range t {
+		production := ""
+		if step.Production != nil {
+			production = transfer.TransferWith(step.Production.Left, step.names) + "->" + transfer.TransferWith(step.Production.Right, step.names)
+		}
+		rows = append(rows, []string{
+			strconv.Itoa(i + 1),
+			transfer.TransferWith(strings.Join(step.Stack, ""), step.names),
+			step.Input,
+			step.Action,
+			production,
+		})
+	}
+	return rows
+}
+
+// String 以终端表格输出 与分析表一样使用 gotable
+func (t Trace) String() string {
+	table, err := gotable.Create(traceColumns...)
+	if err != nil {
+		fmt.Println(err.Error())
+		return ""
+	}
+	for _, row := range t.rows() {
+		value := make(map[string]string)
+		for i, column := range traceColumns {
+			value[column] = row[i]
+		}
+		if err = table.AddRow(value); err != nil {
+			fmt.Println(err.Error())
+			return ""
+		}
+	}
+	return table.String()
+}
+
+// CSV 以 CSV 输出 第一行为表头
+func (t Trace) CSV() string {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	_ = w.Write(traceColumns)
+	_ = w.WriteAll(t.rows())
+	return buf.String()
+}
+
+// Markdown 以 Markdown 表格输出 可以直接放进实验报告
+func (t Trace) Markdown() string {
+	var build strings.Builder
+	line := func(cells []string) {
+		for i := range cells {
+			cells[i] = strings.ReplaceAll(cells[i], "|", `\|`)
+		}
+		build.WriteString("| " + strings.Join(cells, " | ") + " |\n")
+	}
+	line(append([]string(nil), traceColumns...))
+	separator := make([]string, len(traceColumns))
+	for i := range separator {
+		separator[i] = "---"
+	}
+	line(separator)
+	for _, row := range t.rows() {
+		line(row)
+	}
+	return build.String()
+}
+
+// JSON 以 JSON 数组输出 分析栈保留原始的符号
+func (t Trace) JSON() ([]byte, error) {
+	return json.MarshalIndent(t, "", "  ")
+}
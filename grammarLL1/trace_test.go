@@ -0,0 +1,88 @@
+package grammarLL1
+
+import (
+	"bytes"
+	"encoding/json"
+	"github.com/esonhugh/compiler/grammarLL1/analysisTable"
+	"github.com/esonhugh/compiler/grammarLL1/first"
+	"github.com/esonhugh/compiler/grammarLL1/follow"
+	"github.com/esonhugh/compiler/lexer"
+	"strings"
+	"testing"
+)
+
+func traceOf(t *testing.T, code string) Trace {
+	return traceWith(t, expressionLL1, "E", code)
+}
+
+func traceWith(t *testing.T, rules string, start string, code string) Trace {
+	g, _, err := loadRules(rules, start)
+	if err != nil {
+		t.Fatal(err)
+	}
+	firstSet := first.GetFirstSet(g)
+	table := analysisTable.GetAnalyzeTable(firstSet, follow.GetFollowSet(g, start, firstSet), g)
+	grm := NewGrammar(lexer.Analyse(code), bytes.NewBufferString(start), EndToken, table)
+	grm.Analyze()
+	return grm.Steps
+}
+
+func TestTrace(t *testing.T) {
+	trace := traceOf(t, "i+i")
+	t.Log(trace.String())
+	if len(trace) != 16 {
+		t.Fatalf("expected 16 steps, got %d\n%s", len(trace), trace.Markdown())
+	}
+	first, last := trace[0], trace[len(trace)-1]
+	if strings.Join(first.Stack, "") != "#E" || first.Input != "i+i#" || first.Action != Derive || first.Production.Right != "TA" {
+		t.Errorf("unexpected first step %+v", *first)
+	}
+	if strings.Join(last.Stack, "") != "#" || last.Input != "#" || last.Action != Accept {
+		t.Errorf("unexpected last step %+v", *last)
+	}
+	if trace[3].Action != Match || strings.Join(trace[3].Stack, "") != "#ABi" || trace[3].Input != "i+i#" {
+		t.Errorf("unexpected match step %+v", *trace[3])
+	}
+}
+
+func TestTraceError(t *testing.T) {
+	trace := traceOf(t, "i+)")
+	if last := trace[len(trace)-1]; last.Action != Error || last.Input != ")#" {
+		t.Fatalf("unexpected last step %+v", *last)
+	}
+}
+
+func TestTraceRenderers(t *testing.T) {
+	trace := traceOf(t, "i*i")
+	csv := strings.Split(strings.TrimSpace(trace.CSV()), "\n")
+	if len(csv) != len(trace)+1 || csv[0] != "步骤,分析栈,剩余输入,动作,产生式" || csv[1] != "1,#E,i*i#,derive,E->TA" {
+		t.Errorf("unexpected csv\n%s", trace.CSV())
+	}
+	markdown := strings.Split(strings.TrimSpace(trace.Markdown()), "\n")
+	if len(markdown) != len(trace)+2 || markdown[1] != "| --- | --- | --- | --- | --- |" || markdown[2] != "| 1 | #E | i*i# | derive | E->TA |" {
+		t.Errorf("unexpected markdown\n%s", trace.Markdown())
+	}
+	data, err := trace.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded []*TraceStep
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded) != len(trace) || decoded[0].Production.Left != "E" || decoded[len(decoded)-1].Production != nil {
+		t.Errorf("unexpected json\n%s", data)
+	}
+}
+
+func TestTraceNames(t *testing.T) {
+	// 没有 %name 声明时 S 按原样输出
+	trace := traceWith(t, "S->(S)|i", "S", "(i)")
+	if row := trace.rows()[0]; row[1] != "#S" || row[4] != "S->(S)" {
+		t.Errorf("unexpected first row %v", row)
+	}
+	trace = traceWith(t, "%name S T'\nS->(S)|i", "S", "(i)")
+	if row := trace.rows()[0]; row[1] != "#T'" || row[4] != "T'->(T')" {
+		t.Errorf("unexpected first row %v", row)
+	}
+}
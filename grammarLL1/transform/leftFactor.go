@@ -0,0 +1,80 @@
+package transform
+
+import (
+	"github.com/esonhugh/compiler/grammarLL1/rule"
+)
+
+// LeftFactor 提取左公因子
+// 反复找出某个非终结符两个以上候选式的最长公共前缀 α
+//
+//	A->αβ1|αβ2|γ  ==>  A->αA'|γ  A'->β1|β2
+//
+// 直到任意两个候选式都没有公共前缀为止 新建的非终结符同样会继续处理
+func LeftFactor(r *rule.Rule) (*rule.Rule, *Report, error) {
+	g := r.Clone()
+	report := NewReport()
+	for i := 0; i < len(g.Nonterminals()); i++ {
+		a := g.Nonterminals()[i]
+		for {
+			prefix := longestCommonPrefix(g.Rules[a])
+			if prefix == "" {
+				break
+			}
+			origin := formulasOf(g, a)
+			primed, err := report.newNonterminal(g, a)
+			if err != nil {
+				return nil, nil, err
+			}
+			var rights []string
+			for _, right := range g.Rules[a] {
+				if len(right) >= len(prefix) && right[:len(prefix)] == prefix && right != "&" {
+					g.Rules[primed] = appendUnique(g.Rules[primed], concat(right[len(prefix):], ""))
+					continue
+				}
+				rights = append(rights, right)
+			}
+			g.Rules[a] = append([]string{prefix + primed}, rights...)
+			report.Changes = append(report.Changes, &Change{
+				Reason: "提取左公因子 " + report.Name(prefix),
+				Origin: origin,
+				Result: formulasOf(g, a, primed),
+			})
+		}
+	}
+	return g, report, nil
+}
+
+// MakeLL1 依次消除左递归和提取左公因子 合并两份报告
+// 变换后的文法不一定是 LL(1) 的 仍然需要构造分析表检查
+func MakeLL1(r *rule.Rule) (*rule.Rule, *Report, error) {
+	g, report, err := EliminateLeftRecursion(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	g, factor, err := LeftFactor(g)
+	if err != nil {
+		return nil, nil, err
+	}
+	report.Merge(factor)
+	return g, report, nil
+}
+
+// longestCommonPrefix 候选式两两之间最长的公共前缀 没有时返回空串
+func longestCommonPrefix(rights []string) string {
+	best := ""
+	for i := 0; i < len(rights); i++ {
+		for j := i + 1; j < len(rights); j++ {
+			if rights[i] == "&" || rights[j] == "&" {
+				continue
+			}
+			n := 0
+			for n < len(rights[i]) && n < len(rights[j]) && rights[i][n] == rights[j][n] {
+				n++
+			}
+			if n > len(best) {
+				best = rights[i][:n]
+			}
+		}
+	}
+	return best
+}
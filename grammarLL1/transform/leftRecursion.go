@@ -0,0 +1,205 @@
+package transform
+
+import (
+	"errors"
+	"fmt"
+	"github.com/esonhugh/compiler/grammarLL1/first"
+	"github.com/esonhugh/compiler/grammarLL1/rule"
+	"github.com/esonhugh/compiler/grammarLL1/util"
+	"strings"
+)
+
+// EliminateLeftRecursion 消除直接和间接左递归
+// 只处理左递归的强连通分量 分量内按声明顺序 A1..An 代入 再消除直接左递归
+//
+//	A->Aα|β  ==>  A->βA'  A'->αA'|&
+//
+// 经由可空前缀的隐藏左递归 (A->BA B->&) 无法用此方法消除 返回错误
+func EliminateLeftRecursion(r *rule.Rule) (*rule.Rule, *Report, error) {
+	g := r.Clone()
+	report := NewReport()
+	for _, group := range leftRecursiveGroups(g) {
+		origin := formulasOf(g, group...)
+		var reasons []string
+		lefts := append([]string(nil), group...)
+		for i, ai := range group {
+			// 代入 Ai->Ajγ (j<i) 展开为 Ai->δγ
+			for j := 0; j < i; j++ {
+				if substitute(g, ai, group[j]) {
+					reasons = append(reasons, fmt.Sprintf("代入 %s->%s", ai, group[j]))
+				}
+			}
+			primed, err := eliminateImmediate(g, ai, report)
+			if err != nil {
+				return nil, nil, err
+			}
+			if primed != "" {
+				lefts = append(lefts, primed)
+			}
+		}
+		reason := "直接左递归"
+		if len(group) > 1 || len(reasons) != 0 {
+			reason = "间接左递归 " + strings.Join(reasons, " ")
+		}
+		report.Changes = append(report.Changes, &Change{
+			Reason: reason,
+			Origin: origin,
+			Result: formulasOf(g, orderOf(g, lefts)...),
+		})
+	}
+	if left := LeftRecursive(g); len(left) != 0 {
+		return nil, nil, errors.New("left recursion through nullable prefix remains: " + strings.Join(left, " "))
+	}
+	return g, report, nil
+}
+
+// LeftRecursive 返回所有左递归的非终结符 A =>+ Aα
+// 左部可空的前缀也会被考虑在内
+func LeftRecursive(g *rule.Rule) []string {
+	graph := leftCorners(g, first.GetNullableSet(g))
+	var res []string
+	for _, key := range g.Nonterminals() {
+		if reaches(graph, key, key) {
+			res = append(res, key)
+		}
+	}
+	return res
+}
+
+// substitute 把 ai 以 aj 开头的产生式用 aj 的全部产生式展开
+func substitute(g *rule.Rule, ai, aj string) bool {
+	var rights []string
+	changed := false
+	for _, right := range g.Rules[ai] {
+		if !strings.HasPrefix(right, aj) {
+			rights = append(rights, right)
+			continue
+		}
+		changed = true
+		for _, delta := range g.Rules[aj] {
+			rights = appendUnique(rights, concat(delta, right[1:]))
+		}
+	}
+	if changed {
+		g.Rules[ai] = rights
+	}
+	return changed
+}
+
+// eliminateImmediate 消除 a 的直接左递归 返回新建的非终结符
+// 没有直接左递归时返回空串 A->A 这样的产生式直接删除
+func eliminateImmediate(g *rule.Rule, a string, report *Report) (string, error) {
+	var alpha, beta []string
+	for _, right := range g.Rules[a] {
+		if !strings.HasPrefix(right, a) {
+			beta = append(beta, right)
+			continue
+		}
+		if len(right) > 1 {
+			alpha = append(alpha, right[1:])
+		}
+	}
+	if len(alpha) == 0 {
+		g.Rules[a] = beta
+		return "", nil
+	}
+	primed, err := report.newNonterminal(g, a)
+	if err != nil {
+		return "", err
+	}
+	g.Rules[a] = nil
+	for _, b := range beta {
+		g.Rules[a] = appendUnique(g.Rules[a], concat(b, primed))
+	}
+	for _, al := range alpha {
+		g.Rules[primed] = appendUnique(g.Rules[primed], al+primed)
+	}
+	g.Rules[primed] = append(g.Rules[primed], "&")
+	return primed, nil
+}
+
+// leftRecursiveGroups 左角图中含有环的强连通分量 分量内按声明顺序排列
+func leftRecursiveGroups(g *rule.Rule) [][]string {
+	graph := leftCorners(g, nil)
+	var groups [][]string
+	done := make(map[string]bool)
+	for _, a := range g.Nonterminals() {
+		if done[a] || !reaches(graph, a, a) {
+			continue
+		}
+		var group []string
+		for _, b := range g.Nonterminals() {
+			if b == a || (reaches(graph, a, b) && reaches(graph, b, a)) {
+				group = append(group, b)
+				done[b] = true
+			}
+		}
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// leftCorners 左角图 A->Bγ 则有边 A->B
+// nullable 不为空时 跳过可空的前缀继续连边
+func leftCorners(g *rule.Rule, nullable first.Nullable) map[string][]string {
+	graph := make(map[string][]string)
+	for left, rights := range g.Rules {
+		for _, right := range rights {
+			for i := 0; i < len(right); i++ {
+				c := string(right[i])
+				if util.IsTerminal(right[i]) {
+					break
+				}
+				graph[left] = append(graph[left], c)
+				if !nullable[c] {
+					break
+				}
+			}
+		}
+	}
+	return graph
+}
+
+// reaches 图中 from 经过至少一条边能否到达 to
+func reaches(graph map[string][]string, from, to string) bool {
+	visited := make(map[string]bool)
+	queue := append([]string(nil), graph[from]...)
+	for len(queue) != 0 {
+		c := queue[0]
+		queue = queue[1:]
+		if c == to {
+			return true
+		}
+		if visited[c] {
+			continue
+		}
+		visited[c] = true
+		queue = append(queue, graph[c]...)
+	}
+	return false
+}
+
+// orderOf 按规则中的声明顺序排列 lefts
+func orderOf(g *rule.Rule, lefts []string) []string {
+	want := make(map[string]bool)
+	for _, left := range lefts {
+		want[left] = true
+	}
+	var res []string
+	for _, key := range g.Nonterminals() {
+		if want[key] {
+			res = append(res, key)
+		}
+	}
+	return res
+}
+
+// appendUnique 追加不重复的产生式右部
+func appendUnique(list []string, s string) []string {
+	for _, item := range list {
+		if item == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
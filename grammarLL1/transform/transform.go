@@ -0,0 +1,155 @@
+/*
+Package transform 文法变换包 消除左递归 提取左公因子 使自然写法的文法可以直接用于 LL(1) 分析
+
+变换不修改传入的规则 而是返回一份新的规则和一份变换报告
+由于是单个字符匹配 新建的非终结符 (E' 等) 用空闲的大写字母表示 报告中记录了它们的名字
+*/
+package transform
+
+import (
+	"fmt"
+	"github.com/esonhugh/compiler/grammarLL1/rule"
+	"github.com/esonhugh/compiler/util/transfer"
+	"sort"
+	"strings"
+)
+
+// Change 一次变换 原始产生式 ==> 新的产生式
+type Change struct {
+	Reason string
+	Origin []*rule.Formula
+	Result []*rule.Formula
+}
+
+// Report 变换报告
+type Report struct {
+	Names   map[string]string // Names 新建非终结符 => 显示名 例如 G => E'
+	Changes []*Change
+}
+
+// NewReport 创建一个空的报告
+func NewReport() *Report {
+	return &Report{Names: make(map[string]string)}
+}
+
+// Name 符号串的显示形式 新建的非终结符替换为显示名
+func (r *Report) Name(str string) string {
+	return transfer.TransferWith(str, r.Names)
+}
+
+// Merge 合并另一份报告 用于多个变换串联
+func (r *Report) Merge(o *Report) {
+	for key, value := range o.Names {
+		r.Names[key] = value
+	}
+	r.Changes = append(r.Changes, o.Changes...)
+}
+
+// String 输出报告 每个变换一段
+func (r *Report) String() string {
+	var build strings.Builder
+	if len(r.Names) != 0 {
+		var news []string
+		for key := range r.Names {
+			news = append(news, key)
+		}
+		sort.Strings(news)
+		for _, key := range news {
+			build.WriteString(fmt.Sprintf("%s ==> %s\n", r.Names[key], key))
+		}
+	}
+	for _, c := range r.Changes {
+		build.WriteString(fmt.Sprintf("[%s]\n", c.Reason))
+		build.WriteString(r.formulas(c.Origin, "  "))
+		build.WriteString(r.formulas(c.Result, "  ==> "))
+	}
+	return build.String()
+}
+
+// formulas 按左部合并输出产生式 A->x|y
+func (r *Report) formulas(list []*rule.Formula, prefix string) string {
+	var build strings.Builder
+	var order []string
+	rights := make(map[string][]string)
+	for _, f := range list {
+		if _, ok := rights[f.Left]; !ok {
+			order = append(order, f.Left)
+		}
+		rights[f.Left] = append(rights[f.Left], r.Name(f.Right))
+	}
+	for i, left := range order {
+		if i != 0 {
+			prefix = strings.Repeat(" ", len(prefix))
+		}
+		build.WriteString(fmt.Sprintf("%s%s->%s\n", prefix, r.Name(left), strings.Join(rights[left], "|")))
+	}
+	return build.String()
+}
+
+// primeName 为 base 派生的新符号命名 每次多加一个撇号 不与文法中已有的显示名重复
+func primeName(g *rule.Rule, base string) string {
+	name := g.Name(base)
+	for {
+		name += "'"
+		used := false
+		for _, value := range g.Names {
+			if value == name {
+				used = true
+				break
+			}
+		}
+		if !used {
+			return name
+		}
+	}
+}
+
+// newNonterminal 分配一个新的非终结符 并排在 after 的后面
+// 显示名同时记录在报告和新的规则中
+func (r *Report) newNonterminal(g *rule.Rule, after string) (string, error) {
+	sym, err := g.NewNonterminal()
+	if err != nil {
+		return "", err
+	}
+	name := primeName(g, after)
+	r.Names[sym] = name
+	g.Names[sym] = name
+	g.Rules[sym] = nil
+	order := make([]string, 0, len(g.Order)+1)
+	for _, key := range g.Nonterminals() {
+		if key == sym {
+			continue
+		}
+		order = append(order, key)
+		if key == after {
+			order = append(order, sym)
+		}
+	}
+	g.Order = order
+	return sym, nil
+}
+
+// formulasOf 取出若干非终结符的全部产生式
+func formulasOf(g *rule.Rule, lefts ...string) []*rule.Formula {
+	var res []*rule.Formula
+	for _, left := range lefts {
+		for _, right := range g.Rules[left] {
+			res = append(res, &rule.Formula{Left: left, Right: right})
+		}
+	}
+	return res
+}
+
+// concat 连接两个符号串 处理空串 &
+func concat(a, b string) string {
+	if a == "&" {
+		a = ""
+	}
+	if b == "&" {
+		b = ""
+	}
+	if a+b == "" {
+		return "&"
+	}
+	return a + b
+}
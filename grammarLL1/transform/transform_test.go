@@ -0,0 +1,97 @@
+package transform
+
+import (
+	"github.com/esonhugh/compiler/grammarLL1/rule"
+	"testing"
+)
+
+func TestEliminateDirectLeftRecursion(t *testing.T) {
+	g := rule.MustParse("E->E+T|T\nT->T*F|F\nF->(E)|i")
+	res, report, err := EliminateLeftRecursion(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(report.String())
+	want := "%name A E'\n%name B T'\nE->TA\nA->+TA|&\nT->FB\nB->*FB|&\nF->(E)|i\n"
+	if res.String() != want {
+		t.Fatalf("got\n%s\nwant\n%s", res.String(), want)
+	}
+	if report.Names["A"] != "E'" || report.Names["B"] != "T'" {
+		t.Fatalf("unexpected names %v", report.Names)
+	}
+	if g.String() != "E->E+T|T\nT->T*F|F\nF->(E)|i\n" {
+		t.Fatal("origin rule modified")
+	}
+}
+
+func TestEliminateIndirectLeftRecursion(t *testing.T) {
+	g := rule.MustParse("S->Aa|b\nA->Ac|Sd|&")
+	res, report, err := EliminateLeftRecursion(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(report.String())
+	want := "%name B A'\nS->Aa|b\nA->bdB|B\nB->cB|adB|&\n"
+	if res.String() != want {
+		t.Fatalf("got\n%s\nwant\n%s", res.String(), want)
+	}
+	if len(LeftRecursive(res)) != 0 {
+		t.Fatal("left recursion remains")
+	}
+}
+
+func TestEliminateLeftRecursionUntouched(t *testing.T) {
+	g := rule.MustParse("E->TG\nG->ATG|&\nT->FS\nS->MFS|&\nF->(E)|i\nA->+|-\nM->*|/")
+	res, report, err := EliminateLeftRecursion(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.String() != g.String() || len(report.Changes) != 0 {
+		t.Fatalf("grammar without left recursion changed:\n%s", res.String())
+	}
+}
+
+func TestHiddenLeftRecursion(t *testing.T) {
+	g := rule.MustParse("A->BAa|b\nB->&|c")
+	if _, _, err := EliminateLeftRecursion(g); err == nil {
+		t.Fatal("expect error for hidden left recursion")
+	}
+}
+
+func TestLeftFactor(t *testing.T) {
+	g := rule.MustParse("S->iEtS|iEtSeS|a\nE->b")
+	res, report, err := LeftFactor(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(report.String())
+	want := "%name A S'\nS->iEtSA|a\nA->&|eS\nE->b\n"
+	if res.String() != want {
+		t.Fatalf("got\n%s\nwant\n%s", res.String(), want)
+	}
+}
+
+func TestLeftFactorNested(t *testing.T) {
+	g := rule.MustParse("A->abc|abd|ae|f")
+	res, _, err := LeftFactor(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "%name B A'\n%name C A''\nA->aC|f\nC->bB|e\nB->c|d\n"
+	if res.String() != want {
+		t.Fatalf("got\n%s\nwant\n%s", res.String(), want)
+	}
+}
+
+func TestMakeLL1(t *testing.T) {
+	g := rule.MustParse("E->E+T|E-T|T\nT->T*F|T/F|F\nF->(E)|i")
+	res, report, err := MakeLL1(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(report.String())
+	want := "%name A E'\n%name B T'\nE->TA\nA->+TA|-TA|&\nT->FB\nB->*FB|/FB|&\nF->(E)|i\n"
+	if res.String() != want {
+		t.Fatalf("got\n%s\nwant\n%s", res.String(), want)
+	}
+}
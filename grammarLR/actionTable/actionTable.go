@@ -0,0 +1,273 @@
+/*
+Package actionTable LR 分析表构造和处理包 ACTION 表和 GOTO 表
+
+冲突不会中断构造 每个冲突都会被记录下来
+默认的解决方法与 yacc 相同 移进/归约冲突选择移进 归约/归约冲突选择先声明的产生式
+*/
+package actionTable
+
+import (
+	"fmt"
+	"github.com/esonhugh/compiler/grammarLL1/follow"
+	"github.com/esonhugh/compiler/grammarLR/item"
+	"github.com/liushuochen/gotable"
+	"strconv"
+	"strings"
+)
+
+// 动作类型
+const (
+	Shift  = "shift"
+	Reduce = "reduce"
+	Accept = "accept"
+)
+
+// 冲突类型
+const (
+	ShiftReduce  = "shift/reduce"
+	ReduceReduce = "reduce/reduce"
+)
+
+// Action ACTION 表中的一个动作 Target 为移进的状态或者归约的产生式编号
+type Action struct {
+	Type   string
+	Target int
+}
+
+// String s3 r2 acc
+func (a *Action) String() string {
+	switch a.Type {
+	case Shift:
+		return "s" + strconv.Itoa(a.Target)
+	case Reduce:
+		return "r" + strconv.Itoa(a.Target)
+	case Accept:
+		return "acc"
+	}
+	return ""
+}
+
+// Conflict ACTION 表中一个格子有多个动作
+type Conflict struct {
+	State   int
+	Symbol  string
+	Kind    string
+	Actions []*Action
+	Chosen  *Action // Chosen 最终填入表中的动作 nil 表示出错
+	Reason  string  // Reason 按优先级和结合性解决冲突的依据
+}
+
+// Table LR 分析表
+type Table struct {
+	Grammar   *item.Grammar
+	States    int
+	Action    []map[string]*Action
+	Goto      []map[string]int
+	Conflicts []*Conflict
+	Resolved  []*Conflict // Resolved 按优先级和结合性解决了的移进/归约冲突
+}
+
+// SLR 用 FOLLOW 集构造 SLR(1) 分析表
+// 项目 A->α· 在 FOLLOW(A) 中的每个终结符上归约
+func SLR(c *item.Collection, followSet follow.FollowSet) *Table {
+	return Build(c, func(_ *item.State, i item.Item) []string {
+		var res []string
+		for _, t := range c.Grammar.Terminals {
+			if followSet.Contains(c.Grammar.Formulas[i.Formula].Left, t) {
+				res = append(res, t)
+			}
+		}
+		return res
+	})
+}
+
+// LR1 用项目自带的向前看符号构造 LR(1) 分析表
+// 传入 LALR 项目集族时得到 LALR(1) 分析表
+func LR1(c *item.Collection) *Table {
+	return Build(c, func(_ *item.State, i item.Item) []string {
+		return []string{i.Lookahead}
+	})
+}
+
+// Build 根据项目集族构造分析表 lookaheads 给出归约项目在哪些终结符上归约
+// SLR LR(1) LALR(1) 的区别只在于 lookaheads
+func Build(c *item.Collection, lookaheads func(*item.State, item.Item) []string) *Table {
+	g := c.Grammar
+	t := &Table{Grammar: g, States: len(c.States)}
+	for range c.States {
+		t.Action = append(t.Action, make(map[string]*Action))
+		t.Goto = append(t.Goto, make(map[string]int))
+	}
+	for _, state := range c.States {
+		for _, x := range g.Symbols(state.Items) {
+			if isTerminal(g, x) {
+				t.set(state.Index, x, &Action{Type: Shift, Target: state.Goto[x]})
+			} else {
+				t.Goto[state.Index][x] = state.Goto[x]
+			}
+		}
+		for _, i := range state.Items {
+			if !g.IsReduce(i) {
+				continue
+			}
+			if i.Formula == 0 {
+				t.set(state.Index, item.EndToken, &Action{Type: Accept})
+				continue
+			}
+			for _, a := range lookaheads(state, i) {
+				t.set(state.Index, a, &Action{Type: Reduce, Target: i.Formula})
+			}
+		}
+	}
+	t.resolve()
+	return t
+}
+
+// set 填入一个动作 已有不同动作时记录冲突
+func (t *Table) set(state int, symbol string, action *Action) {
+	old := t.Action[state][symbol]
+	if old == nil {
+		t.Action[state][symbol] = action
+		return
+	}
+	if *old == *action {
+		return
+	}
+	conflict := t.conflict(state, symbol)
+	if conflict == nil {
+		conflict = &Conflict{State: state, Symbol: symbol, Actions: []*Action{old}}
+		t.Conflicts = append(t.Conflicts, conflict)
+	}
+	for _, a := range conflict.Actions {
+		if *a == *action {
+			return
+		}
+	}
+	conflict.Actions = append(conflict.Actions, action)
+	conflict.Kind = ReduceReduce
+	for _, a := range conflict.Actions {
+		if a.Type == Shift {
+			conflict.Kind = ShiftReduce
+		}
+	}
+	conflict.Chosen = prefer(old, action)
+	t.Action[state][symbol] = conflict.Chosen
+}
+
+// conflict 找到某个格子已经记录的冲突
+func (t *Table) conflict(state int, symbol string) *Conflict {
+	for _, c := range t.Conflicts {
+		if c.State == state && c.Symbol == symbol {
+			return c
+		}
+	}
+	return nil
+}
+
+// prefer 默认的冲突解决 移进优先 否则选择编号小的产生式
+func prefer(a, b *Action) *Action {
+	if a.Type == Shift || a.Type == Accept {
+		return a
+	}
+	if b.Type == Shift || b.Type == Accept {
+		return b
+	}
+	if a.Target <= b.Target {
+		return a
+	}
+	return b
+}
+
+// Actions 格子中的全部动作 GLR 分析器在冲突的格子上分叉
+// 按优先级解决了的冲突只返回选中的动作
+func (t *Table) Actions(state int, symbol string) []*Action {
+	if c := t.conflict(state, symbol); c != nil {
+		return c.Actions
+	}
+	if a := t.Action[state][symbol]; a != nil {
+		return []*Action{a}
+	}
+	return nil
+}
+
+// IsConflictFree 分析表没有冲突
+func (t *Table) IsConflictFree() bool {
+	return len(t.Conflicts) == 0
+}
+
+// ConflictString 输出全部冲突
+func (t *Table) ConflictString() string {
+	return t.conflictString(t.Conflicts)
+}
+
+func (t *Table) conflictString(conflicts []*Conflict) string {
+	var build strings.Builder
+	for _, c := range conflicts {
+		var actions []string
+		for _, a := range c.Actions {
+			if a.Type == Reduce {
+				actions = append(actions, fmt.Sprintf("%s(%s)", a.String(), t.Grammar.FormulaString(a.Target)))
+			} else {
+				actions = append(actions, a.String())
+			}
+		}
+		chosen := "error"
+		if c.Chosen != nil {
+			chosen = c.Chosen.String()
+		}
+		build.WriteString(fmt.Sprintf("%s conflict at ACTION[%d, %s]: %s, choose %s",
+			c.Kind, c.State, t.Grammar.Name(c.Symbol), strings.Join(actions, " "), chosen))
+		if c.Reason != "" {
+			build.WriteString(" (" + c.Reason + ")")
+		}
+		build.WriteString("\n")
+	}
+	return build.String()
+}
+
+// String 输出 ACTION 和 GOTO 表 格式与 analysisTable.SymbolTable 相同
+func (t *Table) String() string {
+	g := t.Grammar
+	column := []string{" "}
+	for _, x := range g.Terminals {
+		column = append(column, g.Name(x))
+	}
+	for _, x := range g.Nonterminals {
+		column = append(column, g.Name(x))
+	}
+	table, err := gotable.Create(column...)
+	if err != nil {
+		fmt.Println(err.Error())
+		return ""
+	}
+	for state := 0; state < t.States; state++ {
+		row := make(map[string]string)
+		row[" "] = strconv.Itoa(state)
+		for _, x := range g.Terminals {
+			row[g.Name(x)] = ""
+			if a := t.Action[state][x]; a != nil {
+				row[g.Name(x)] = a.String()
+			}
+		}
+		for _, x := range g.Nonterminals {
+			row[g.Name(x)] = ""
+			if to, ok := t.Goto[state][x]; ok {
+				row[g.Name(x)] = strconv.Itoa(to)
+			}
+		}
+		if err = table.AddRow(row); err != nil {
+			fmt.Println(err.Error())
+			return ""
+		}
+	}
+	return table.String()
+}
+
+func isTerminal(g *item.Grammar, x string) bool {
+	for _, t := range g.Terminals {
+		if t == x {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,77 @@
+package actionTable
+
+import (
+	"fmt"
+	"github.com/esonhugh/compiler/grammarLL1/rule"
+	"strings"
+)
+
+// resolve 用 %left %right %nonassoc 和 %prec 解决移进/归约冲突
+// 与 yacc 相同 比较产生式和向前看终结符的优先级
+// 产生式优先级高则归约 低则移进 相同时左结合归约 右结合移进 不结合则报错
+// 任何一方没有优先级或者同时有多个归约时冲突保留
+func (t *Table) resolve() {
+	r := t.Grammar.Rules
+	var remain []*Conflict
+	for _, c := range t.Conflicts {
+		if c.Kind != ShiftReduce || len(c.Actions) != 2 {
+			remain = append(remain, c)
+			continue
+		}
+		var shift, reduce *Action
+		for _, a := range c.Actions {
+			if a.Type == Shift {
+				shift = a
+			} else if a.Type == Reduce {
+				reduce = a
+			}
+		}
+		if shift == nil || reduce == nil {
+			remain = append(remain, c)
+			continue
+		}
+		formula := *t.Grammar.Formulas[reduce.Target]
+		fp, ok1 := r.PrecedenceOf(formula)
+		sp, ok2 := r.Precedences[c.Symbol]
+		if !ok1 || !ok2 {
+			remain = append(remain, c)
+			continue
+		}
+		production := fmt.Sprintf("prec(%s)=%d", t.Grammar.FormulaString(reduce.Target), fp.Level)
+		symbol := fmt.Sprintf("prec(%s)=%d", t.Grammar.Name(c.Symbol), sp.Level)
+		switch {
+		case fp.Level > sp.Level:
+			c.Chosen = reduce
+			c.Reason = production + " > " + symbol
+		case fp.Level < sp.Level:
+			c.Chosen = shift
+			c.Reason = production + " < " + symbol
+		case sp.Assoc == rule.Left:
+			c.Chosen = reduce
+			c.Reason = production + " = " + symbol + ", %left"
+		case sp.Assoc == rule.Right:
+			c.Chosen = shift
+			c.Reason = production + " = " + symbol + ", %right"
+		default:
+			c.Chosen = nil
+			c.Reason = production + " = " + symbol + ", %nonassoc"
+		}
+		if c.Chosen == nil {
+			delete(t.Action[c.State], c.Symbol)
+		} else {
+			t.Action[c.State][c.Symbol] = c.Chosen
+		}
+		t.Resolved = append(t.Resolved, c)
+	}
+	t.Conflicts = remain
+}
+
+// PrecedenceReport 输出哪些冲突由优先级解决了 哪些仍然存在
+func (t *Table) PrecedenceReport() string {
+	var build strings.Builder
+	build.WriteString(fmt.Sprintf("resolved by precedence: %d\n", len(t.Resolved)))
+	build.WriteString(t.conflictString(t.Resolved))
+	build.WriteString(fmt.Sprintf("remaining conflicts: %d\n", len(t.Conflicts)))
+	build.WriteString(t.conflictString(t.Conflicts))
+	return build.String()
+}
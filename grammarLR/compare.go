@@ -0,0 +1,68 @@
+package grammarLR
+
+import (
+	"fmt"
+	"github.com/esonhugh/compiler/grammarLR/actionTable"
+	"github.com/esonhugh/compiler/grammarLR/item"
+	"strings"
+)
+
+// Comparison 同一个文法的 SLR(1) LR(1) LALR(1) 分析表对比
+type Comparison struct {
+	SLR  *actionTable.Table
+	LR1  *actionTable.Table
+	LALR *actionTable.Table
+	// Merging LALR 合并同心状态后新出现的冲突
+	Merging []*actionTable.Conflict
+	lalr    *item.Collection
+}
+
+// Compare 构造三种分析表 找出 LALR 合并状态引入的冲突
+// 用于说明一个文法为什么是 LR(1) 的却不是 LALR(1) 的
+func Compare(rules string, start string) (*Comparison, error) {
+	c := &Comparison{}
+	var err error
+	if c.SLR, _, err = BuildTable(rules, start, SLR1); err != nil {
+		return nil, err
+	}
+	if c.LR1, _, err = BuildTable(rules, start, LR1); err != nil {
+		return nil, err
+	}
+	if c.LALR, c.lalr, err = BuildTable(rules, start, LALR1); err != nil {
+		return nil, err
+	}
+	for _, conflict := range c.LALR.Conflicts {
+		introduced := true
+		for _, m := range c.lalr.States[conflict.State].Merged {
+			for _, old := range c.LR1.Conflicts {
+				if old.State == m && old.Symbol == conflict.Symbol {
+					introduced = false
+				}
+			}
+		}
+		if introduced {
+			c.Merging = append(c.Merging, conflict)
+		}
+	}
+	return c, nil
+}
+
+// String 输出对比结果 状态数和冲突数 以及合并引入的冲突来自哪些 LR(1) 状态
+func (c *Comparison) String() string {
+	var build strings.Builder
+	for _, t := range []struct {
+		name  string
+		table *actionTable.Table
+	}{{SLR1, c.SLR}, {LR1, c.LR1}, {LALR1, c.LALR}} {
+		build.WriteString(fmt.Sprintf("%-8s states: %-4d conflicts: %d\n", t.name, t.table.States, len(t.table.Conflicts)))
+	}
+	for _, conflict := range c.Merging {
+		var merged []string
+		for _, m := range c.lalr.States[conflict.State].Merged {
+			merged = append(merged, fmt.Sprintf("I%d", m))
+		}
+		build.WriteString(fmt.Sprintf("merging %s of LR(1) into LALR(1) state %d introduces a %s conflict on %s\n",
+			strings.Join(merged, " "), conflict.State, conflict.Kind, c.LALR.Grammar.Name(conflict.Symbol)))
+	}
+	return build.String()
+}
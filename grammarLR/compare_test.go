@@ -0,0 +1,50 @@
+package grammarLR
+
+import (
+	"github.com/esonhugh/compiler/grammarLR/actionTable"
+	"github.com/esonhugh/compiler/lexer"
+	"testing"
+)
+
+func TestLR1AndLALR(t *testing.T) {
+	// 不是 SLR(1) 但是 LALR(1) 的文法
+	c, err := Compare("P->L=R|R\nL->*R|i\nR->L", "P")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(c.String())
+	if c.SLR.IsConflictFree() || !c.LR1.IsConflictFree() || !c.LALR.IsConflictFree() {
+		t.Fatal("grammar should be LALR(1) but not SLR(1)")
+	}
+	if c.LR1.States != 14 || c.LALR.States != 10 {
+		t.Fatalf("expect 14 LR(1) states and 10 LALR(1) states, got %d %d", c.LR1.States, c.LALR.States)
+	}
+}
+
+func TestMergingConflict(t *testing.T) {
+	// LR(1) 但不是 LALR(1) 的文法 A->c· 与 B->c· 的状态合并后归约/归约冲突
+	c, err := Compare("P->aAd|bBd|aBe|bAe\nA->c\nB->c", "P")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(c.String())
+	if !c.LR1.IsConflictFree() || c.LALR.IsConflictFree() {
+		t.Fatal("grammar should be LR(1) but not LALR(1)")
+	}
+	if len(c.Merging) != 2 || c.Merging[0].Kind != actionTable.ReduceReduce {
+		t.Fatalf("expect reduce/reduce conflicts introduced by merging\n%s", c.LALR.ConflictString())
+	}
+	t.Log(c.LR1.String())
+}
+
+func TestAnalyzeLR1(t *testing.T) {
+	table, _, err := BuildTable("P->aAd|bBd|aBe|bAe\nA->c\nB->c", "P", LR1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, code := range []string{"a c d", "b c e", "a c e", "b c d"} {
+		if _, ok := NewGrammar(lexer.Analyse(code), table).Analyze(); !ok {
+			t.Errorf("%s should be accepted", code)
+		}
+	}
+}
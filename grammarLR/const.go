@@ -0,0 +1,22 @@
+/*
+Package grammarLR 一个自底向上的 LR 语法分析器包
+
+与 grammarLL1 使用同一套规则 FIRST FOLLOW 集 分析表由 actionTable 构造
+*/
+package grammarLR
+
+// Production 归约使用的产生式
+type Production struct {
+	Type   string
+	Target string
+	Origin string
+	Next   string
+}
+
+// Step 分析过程中的一步 状态栈 符号栈 剩余输入和动作
+type Step struct {
+	States  []int
+	Symbols []string
+	Input   string
+	Action  string
+}
@@ -0,0 +1,176 @@
+package grammarLR
+
+import (
+	"fmt"
+	"github.com/esonhugh/compiler/grammarLR/actionTable"
+	"github.com/esonhugh/compiler/lexer"
+	"github.com/esonhugh/compiler/tree"
+	"github.com/gookit/color"
+)
+
+// gssNode 图结构栈的结点 同一层中相同状态的栈顶合并为一个结点
+type gssNode struct {
+	state int
+	level int
+	edges []*gssEdge
+}
+
+// gssEdge 指向栈中下面的结点 边上是对应的森林结点
+type gssEdge struct {
+	to    *gssNode
+	label *tree.ForestNode
+}
+
+// gssPath 归约时从栈顶向下长度为 n 的一条路径
+type gssPath struct {
+	end    *gssNode
+	labels []*tree.ForestNode
+	key    string
+}
+
+// GLR 广义 LR 分析器 在有冲突的 ACTION 格子上分叉 结果是共享压缩语法森林
+type GLR struct {
+	table  *actionTable.Table
+	tokens []*lexer.Token
+	forest *tree.Forest
+}
+
+// AnalyzeGLR 用 LALR(1) 分析表做 GLR 分析 文法有冲突也可以分析
+func AnalyzeGLR(raw []*lexer.Token, rules string, start string) (*tree.Forest, bool) {
+	table, _, err := BuildTable(rules, start, LALR1)
+	if err != nil {
+		color.Redln(err.Error())
+		return nil, false
+	}
+	forest, err := NewGLR(raw, table).Parse()
+	if err != nil {
+		color.Redln(err.Error())
+		return nil, false
+	}
+	return forest, true
+}
+
+// NewGLR 创建一个 GLR 分析器
+func NewGLR(token []*lexer.Token, table *actionTable.Table) *GLR {
+	tokens := append([]*lexer.Token(nil), token...)
+	tokens = append(tokens, &lexer.Token{
+		Typ:   lexer.END,
+		Value: EndToken,
+	})
+	return &GLR{table: table, tokens: tokens, forest: tree.NewForest()}
+}
+
+// Parse 分析 每读入一个词法单元 先在当前层做完所有的归约 再对所有栈顶移进
+func (g *GLR) Parse() (*tree.Forest, error) {
+	level := []*gssNode{{state: 0}}
+	for j := 0; j < len(g.tokens); j++ {
+		current := TerminalOf(g.table.Grammar, g.tokens[j])
+		level = g.reduceAll(level, j, current)
+		if current == EndToken {
+			for _, v := range level {
+				for _, a := range g.table.Actions(v.state, current) {
+					if a.Type == actionTable.Accept {
+						g.forest.Root = v.edges[0].label
+						return g.forest, nil
+					}
+				}
+			}
+			break
+		}
+		level = g.shiftAll(level, j, current)
+		if len(level) == 0 {
+			return nil, fmt.Errorf("glr: unexpected token %s at %d", g.tokens[j].Value, j)
+		}
+	}
+	return nil, fmt.Errorf("glr: unexpected end of input")
+}
+
+// reduceAll 反复执行当前层所有可能的归约 直到不再产生新的归约为止
+// 新的边可能让已经处理过的结点出现新的归约路径 所以按路径去重而不是按结点
+func (g *GLR) reduceAll(level []*gssNode, j int, current string) []*gssNode {
+	done := make(map[string]bool)
+	for changed := true; changed; {
+		changed = false
+		for k := 0; k < len(level); k++ {
+			v := level[k]
+			for _, a := range g.table.Actions(v.state, current) {
+				if a.Type != actionTable.Reduce {
+					continue
+				}
+				formula := g.table.Grammar.Formulas[a.Target]
+				for _, path := range paths(v, len(g.table.Grammar.Right(a.Target))) {
+					key := fmt.Sprintf("%d%s", a.Target, path.key)
+					if done[key] {
+						continue
+					}
+					done[key] = true
+					changed = true
+					node, _ := g.forest.Node(formula.Left, path.end.level, j)
+					g.forest.Pack(node, formula, path.labels)
+					to, ok := g.table.Goto[path.end.state][formula.Left]
+					if !ok {
+						continue
+					}
+					level = push(level, to, j, path.end, node)
+				}
+			}
+		}
+	}
+	return level
+}
+
+// shiftAll 所有能移进当前词法单元的栈顶 移进到下一层
+func (g *GLR) shiftAll(level []*gssNode, j int, current string) []*gssNode {
+	var next []*gssNode
+	leaf, created := g.forest.Node(current, j, j+1)
+	if created {
+		leaf.Token = g.tokens[j]
+	}
+	for _, v := range level {
+		for _, a := range g.table.Actions(v.state, current) {
+			if a.Type == actionTable.Shift {
+				next = push(next, a.Target, j+1, v, leaf)
+			}
+		}
+	}
+	return next
+}
+
+// push 在层中加入状态 state 的结点 已有时合并 只加入新的边
+func push(level []*gssNode, state, j int, below *gssNode, label *tree.ForestNode) []*gssNode {
+	var v *gssNode
+	for _, n := range level {
+		if n.state == state {
+			v = n
+		}
+	}
+	if v == nil {
+		v = &gssNode{state: state, level: j}
+		level = append(level, v)
+	}
+	for _, e := range v.edges {
+		if e.to == below && e.label == label {
+			return level
+		}
+	}
+	v.edges = append(v.edges, &gssEdge{to: below, label: label})
+	return level
+}
+
+// paths 从 v 出发向下长度为 n 的全部路径 边上的森林结点按从左到右的顺序
+func paths(v *gssNode, n int) []*gssPath {
+	if n == 0 {
+		return []*gssPath{{end: v, key: fmt.Sprintf(":%p", v)}}
+	}
+	var res []*gssPath
+	for _, e := range v.edges {
+		for _, p := range paths(e.to, n-1) {
+			res = append(res, &gssPath{
+				end:    p.end,
+				labels: append(append([]*tree.ForestNode(nil), p.labels...), e.label),
+				key:    fmt.Sprintf("%s:%p", p.key, e),
+			})
+		}
+	}
+	return res
+}
@@ -0,0 +1,96 @@
+package grammarLR
+
+import (
+	"github.com/esonhugh/compiler/grammarEarley"
+	"github.com/esonhugh/compiler/lexer"
+	"github.com/esonhugh/compiler/tree"
+	"sort"
+	"testing"
+)
+
+func glrTrees(t *testing.T, rules, start, code string) []*tree.Node {
+	table, _, err := BuildTable(rules, start, LALR1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forest, err := NewGLR(lexer.Analyse(code), table).Parse()
+	if err != nil {
+		return nil
+	}
+	return forest.Trees(0)
+}
+
+// sameTrees 两组语法树相同 不考虑顺序
+func sameTrees(a, b []*tree.Node) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	var sa, sb []string
+	for i := range a {
+		sa = append(sa, a[i].String())
+		sb = append(sb, b[i].String())
+	}
+	sort.Strings(sa)
+	sort.Strings(sb)
+	for i := range sa {
+		if sa[i] != sb[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestGLRAmbiguousExpression(t *testing.T) {
+	rules := "E->E+E|E*E|(E)|i"
+	for code, want := range map[string]int{"a": 1, "a+b*c": 2, "a+b+c+d": 5, "(a+b)*c": 1} {
+		trees := glrTrees(t, rules, "E", code)
+		if len(trees) != want {
+			t.Errorf("%s: got %d trees, want %d", code, len(trees), want)
+		}
+		forest, _ := grammarEarley.Analyze(lexer.Analyse(code), rules, "E")
+		if !sameTrees(trees, forest.Trees(0)) {
+			t.Errorf("%s: GLR and Earley disagree", code)
+		}
+	}
+	if glrTrees(t, rules, "E", "a+*b") != nil {
+		t.Error("a+*b should be rejected")
+	}
+}
+
+func TestGLRDanglingElse(t *testing.T) {
+	rules := "P->iEtP|iEtPeP|a\nE->b"
+	trees := glrTrees(t, rules, "P", "i b t i b t a e a")
+	for _, tr := range trees {
+		t.Log(tr.String())
+	}
+	if len(trees) != 2 {
+		t.Fatalf("dangling else should have 2 trees, got %d", len(trees))
+	}
+	if len(glrTrees(t, rules, "P", "i b t a e a")) != 1 {
+		t.Fatal("single if-else should have 1 tree")
+	}
+}
+
+func TestGLRDeterministic(t *testing.T) {
+	table, _ := SLRTable(expression, "E")
+	g := NewGrammar(lexer.Analyse("(a+b)*c"), table)
+	if _, ok := g.Analyze(); !ok {
+		t.Fatal("(a+b)*c should be accepted")
+	}
+	trees := glrTrees(t, expression, "E", "(a+b)*c")
+	if len(trees) != 1 || !trees[0].Equal(g.Tree) {
+		t.Fatalf("GLR should agree with the LR driver on conflict free grammar")
+	}
+}
+
+func TestGLREmptyProductions(t *testing.T) {
+	rules := "P->AP|b\nA->&|a"
+	trees := glrTrees(t, rules, "P", "a b")
+	if len(trees) == 0 {
+		t.Fatal("a b should be accepted")
+	}
+	forest, _ := grammarEarley.Analyze(lexer.Analyse("a b"), rules, "P")
+	if !sameTrees(trees, forest.Trees(0)) {
+		t.Fatal("GLR and Earley disagree")
+	}
+}
@@ -0,0 +1,214 @@
+package grammarLR
+
+import (
+	"errors"
+	"fmt"
+	"github.com/esonhugh/compiler/grammarLL1/follow"
+	"github.com/esonhugh/compiler/grammarLL1/hygiene"
+	"github.com/esonhugh/compiler/grammarLL1/rule"
+	"github.com/esonhugh/compiler/grammarLR/actionTable"
+	"github.com/esonhugh/compiler/grammarLR/item"
+	"github.com/esonhugh/compiler/lexer"
+	"github.com/esonhugh/compiler/tree"
+	"github.com/gookit/color"
+	"github.com/liushuochen/gotable"
+	"strconv"
+	"strings"
+)
+
+// EndToken 句子结束符号
+const EndToken = item.EndToken
+
+// Grammar LR 分析器 状态栈和符号栈 以及构造出的语法树
+type Grammar struct {
+	table   *actionTable.Table
+	tokens  []*lexer.Token
+	pos     int
+	states  []int
+	symbols []string
+	nodes   []*tree.Node
+	Steps   []*Step
+	Tree    *tree.Node
+}
+
+// Analyze 用 SLR(1) 分析表分析 返回按顺序的归约
+func Analyze(raw []*lexer.Token, rules string, start string) ([]*Production, bool) {
+	table, err := SLRTable(rules, start)
+	if err != nil {
+		color.Redln(err.Error())
+		return nil, false
+	}
+	fmt.Println(table.String())
+	if !table.IsConflictFree() {
+		color.Redln(table.ConflictString())
+		return nil, false
+	}
+	res, ok := NewGrammar(raw, table).Analyze()
+	if !ok {
+		color.Redln("Wrong grammar")
+	}
+	return res, ok
+}
+
+// 分析表的构造方法
+const (
+	SLR1  = "SLR(1)"
+	LR1   = "LR(1)"
+	LALR1 = "LALR(1)"
+)
+
+// SLRTable 根据规则构造 SLR(1) 分析表
+func SLRTable(rules string, start string) (*actionTable.Table, error) {
+	table, _, err := BuildTable(rules, start, SLR1)
+	return table, err
+}
+
+// BuildTable 根据规则按 method 构造分析表 同时返回使用的项目集族
+// 开始符号未定义或者文法中有未定义的非终结符时返回错误
+func BuildTable(rules string, start string, method string) (*actionTable.Table, *item.Collection, error) {
+	r := rule.NewRules()
+	if err := r.AddRules(rules); err != nil {
+		return nil, nil, err
+	}
+	if report := hygiene.Check(r, start); !report.IsUsable() {
+		return nil, nil, errors.New("grammar is not usable:\n" + report.String())
+	}
+	g, err := item.Augment(r, start)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch method {
+	case SLR1:
+		c := item.LR0(g)
+		followSet := follow.GetFollowSet(r, start, g.First)
+		return actionTable.SLR(c, followSet), c, nil
+	case LR1:
+		c := item.LR1(g)
+		return actionTable.LR1(c), c, nil
+	case LALR1:
+		c := item.LALR(item.LR1(g))
+		return actionTable.LR1(c), c, nil
+	}
+	return nil, nil, errors.New("unknown LR method " + method)
+}
+
+// NewGrammar 创建一个新的 LR 分析器
+func NewGrammar(token []*lexer.Token, table *actionTable.Table) *Grammar {
+	tokens := append([]*lexer.Token(nil), token...)
+	tokens = append(tokens, &lexer.Token{
+		Typ:   lexer.END,
+		Value: EndToken,
+	})
+	return &Grammar{table: table, tokens: tokens, states: []int{0}, symbols: []string{EndToken}}
+}
+
+// Analyze 分析 返回按顺序的归约 同时记录每一步和语法树
+func (g *Grammar) Analyze() (res []*Production, ok bool) {
+	for {
+		state := g.states[len(g.states)-1]
+		current := TerminalOf(g.table.Grammar, g.tokens[g.pos])
+		action := g.table.Action[state][current]
+		g.record(action)
+		if action == nil {
+			return res, false
+		}
+		switch action.Type {
+		case actionTable.Shift:
+			g.states = append(g.states, action.Target)
+			g.symbols = append(g.symbols, current)
+			g.nodes = append(g.nodes, tree.Leaf(current, g.tokens[g.pos]))
+			g.pos++
+		case actionTable.Reduce:
+			formula := g.table.Grammar.Formulas[action.Target]
+			n := len(g.table.Grammar.Right(action.Target))
+			node := ReduceNode(g.table.Grammar, action.Target, g.nodes[len(g.nodes)-n:])
+			g.states = g.states[:len(g.states)-n]
+			g.symbols = g.symbols[:len(g.symbols)-n]
+			g.nodes = append(g.nodes[:len(g.nodes)-n], node)
+			to, ok := g.table.Goto[g.states[len(g.states)-1]][formula.Left]
+			if !ok {
+				return res, false
+			}
+			g.states = append(g.states, to)
+			g.symbols = append(g.symbols, formula.Left)
+			res = append(res, &Production{
+				Type:   "reduce",
+				Origin: formula.Left,
+				Next:   formula.Right,
+			})
+		case actionTable.Accept:
+			g.Tree = g.nodes[len(g.nodes)-1]
+			return res, true
+		}
+	}
+}
+
+// Failure 分析失败时停下的词法单元 以及栈顶状态下可以接受的终结符
+func (g *Grammar) Failure() (*lexer.Token, []string) {
+	state := g.states[len(g.states)-1]
+	var expected []string
+	for _, terminal := range g.table.Grammar.Terminals {
+		if g.table.Action[state][terminal] != nil {
+			expected = append(expected, terminal)
+		}
+	}
+	return g.tokens[g.pos], expected
+}
+
+// record 记录当前的状态栈 符号栈 剩余输入和将要执行的动作
+func (g *Grammar) record(action *actionTable.Action) {
+	var input strings.Builder
+	for _, t := range g.tokens[g.pos:] {
+		input.WriteString(t.Value)
+	}
+	step := &Step{
+		States:  append([]int(nil), g.states...),
+		Symbols: append([]string(nil), g.symbols...),
+		Input:   input.String(),
+		Action:  "error",
+	}
+	if action != nil {
+		step.Action = action.String()
+		if action.Type == actionTable.Reduce {
+			step.Action += " " + g.table.Grammar.FormulaString(action.Target)
+		}
+	}
+	g.Steps = append(g.Steps, step)
+}
+
+// TraceString 以表格输出分析过程
+func (g *Grammar) TraceString() string {
+	table, err := gotable.Create("步骤", "状态栈", "符号栈", "剩余输入", "动作")
+	if err != nil {
+		fmt.Println(err.Error())
+		return ""
+	}
+	for i, step := range g.Steps {
+		var states []string
+		for _, s := range step.States {
+			states = append(states, strconv.Itoa(s))
+		}
+		err = table.AddRow(map[string]string{
+			"步骤":   strconv.Itoa(i + 1),
+			"状态栈":  strings.Join(states, " "),
+			"符号栈":  g.table.Grammar.Name(strings.Join(step.Symbols, "")),
+			"剩余输入": step.Input,
+			"动作":   step.Action,
+		})
+		if err != nil {
+			fmt.Println(err.Error())
+			return ""
+		}
+	}
+	return table.String()
+}
+
+// ReduceNode 按第 i 个产生式归约 children 为右部对应的结点 空产生式得到 ε 叶子
+func ReduceNode(g *item.Grammar, i int, children []*tree.Node) *tree.Node {
+	return tree.Reduce(g.Formulas[i].Left, children)
+}
+
+// TerminalOf 词法单元对应的终结符 由文法的 Matcher 决定
+func TerminalOf(g *item.Grammar, t *lexer.Token) string {
+	return g.Matcher.TerminalOf(t)
+}
@@ -0,0 +1,88 @@
+package grammarLR
+
+import (
+	"github.com/esonhugh/compiler/grammarLR/actionTable"
+	"github.com/esonhugh/compiler/lexer"
+	"strings"
+	"testing"
+)
+
+const expression = "E->E+T|T\nT->T*F|F\nF->(E)|i"
+
+func TestSLRTable(t *testing.T) {
+	table, err := SLRTable(expression, "E")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(table.String())
+	if table.States != 12 || !table.IsConflictFree() {
+		t.Fatalf("expect 12 states without conflict, got %d\n%s", table.States, table.ConflictString())
+	}
+}
+
+func TestSLRConflict(t *testing.T) {
+	// 经典的非 SLR(1) 文法 L=R 与 R->L 在 = 上移进/归约冲突
+	table, err := SLRTable("P->L=R|R\nL->*R|i\nR->L", "P")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(table.ConflictString())
+	if len(table.Conflicts) != 1 || table.Conflicts[0].Kind != actionTable.ShiftReduce || table.Conflicts[0].Symbol != "=" {
+		t.Fatalf("unexpected conflicts\n%s", table.ConflictString())
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	table, _ := SLRTable(expression, "E")
+	g := NewGrammar(lexer.Analyse("a+b*c"), table)
+	res, ok := g.Analyze()
+	if !ok {
+		t.Fatal("a+b*c should be accepted")
+	}
+	t.Log(g.TraceString())
+	var reductions []string
+	for _, p := range res {
+		reductions = append(reductions, p.Origin+"->"+p.Next)
+	}
+	want := "F->i T->F E->T F->i T->F F->i T->T*F E->E+T"
+	if strings.Join(reductions, " ") != want {
+		t.Fatalf("got %s", strings.Join(reductions, " "))
+	}
+	if g.Tree.String() != "E(E(T(F(i))) + T(T(F(i)) * F(i)))" {
+		t.Fatalf("unexpected tree %s", g.Tree.String())
+	}
+	if _, ok := NewGrammar(lexer.Analyse("a+*b"), table).Analyze(); ok {
+		t.Fatal("a+*b should be rejected")
+	}
+}
+
+func TestTokenDeclarations(t *testing.T) {
+	table, err := SLRTable("%token n INTEGER|FLOAT\n%token i VARIABLE\nE->E+T|T\nT->n|i", "E")
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewGrammar(lexer.Analyse("a+1+2.5"), table)
+	if _, ok := g.Analyze(); !ok {
+		t.Fatal("a+1+2.5 should be accepted")
+	}
+	if g.Tree.String() != "E(E(E(T(i)) + T(n)) + T(n))" {
+		t.Fatalf("unexpected tree %s", g.Tree.String())
+	}
+	if _, ok := NewGrammar(lexer.Analyse("a+\"s\""), table).Analyze(); ok {
+		t.Fatal("a string matches neither n nor i")
+	}
+}
+
+func TestUndefinedStart(t *testing.T) {
+	for _, method := range []string{SLR1, LR1, LALR1} {
+		if _, _, err := BuildTable("E->i", "X", method); err == nil {
+			t.Errorf("%s: undefined start symbol should be rejected", method)
+		}
+		if _, _, err := BuildTable("E->iX", "E", method); err == nil {
+			t.Errorf("%s: undefined nonterminal should be rejected", method)
+		}
+	}
+	if _, ok := AnalyzeGLR(lexer.Analyse("a"), "E->i", "X"); ok {
+		t.Error("GLR: undefined start symbol should be rejected")
+	}
+}
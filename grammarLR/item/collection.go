@@ -0,0 +1,108 @@
+package item
+
+import (
+	"fmt"
+	"strings"
+)
+
+// State 规范项目集族中的一个状态
+type State struct {
+	Index int
+	Items Set
+	Goto  map[string]int // Goto 读入符号后转移到的状态
+	// Merged LALR 状态由哪些 LR(1) 状态合并而来 其他项目集族为空
+	Merged []int
+}
+
+// Collection 规范项目集族
+type Collection struct {
+	Grammar *Grammar
+	States  []*State
+}
+
+// LR0 构造 LR(0) 规范项目集族
+func LR0(g *Grammar) *Collection {
+	return build(g, []Item{{Formula: 0}}, g.Closure)
+}
+
+// LR1 构造规范 LR(1) 项目集族
+func LR1(g *Grammar) *Collection {
+	return build(g, []Item{{Formula: 0, Lookahead: EndToken}}, g.Closure1)
+}
+
+// LALR 合并 LR(1) 项目集族中核心相同的状态 得到 LALR(1) 项目集族
+// 合并后的状态按第一次出现的顺序编号 向前看符号取并集
+func LALR(lr1 *Collection) *Collection {
+	c := &Collection{Grammar: lr1.Grammar}
+	index := make(map[string]int)
+	mapping := make([]int, len(lr1.States))
+	var items [][]Item
+	for _, state := range lr1.States {
+		key := state.Items.CoreKey()
+		i, ok := index[key]
+		if !ok {
+			i = len(c.States)
+			index[key] = i
+			c.States = append(c.States, &State{Index: i, Goto: make(map[string]int)})
+			items = append(items, nil)
+		}
+		mapping[state.Index] = i
+		c.States[i].Merged = append(c.States[i].Merged, state.Index)
+		items[i] = append(items[i], state.Items...)
+	}
+	for i, state := range c.States {
+		state.Items = NewSet(items[i])
+	}
+	for _, state := range lr1.States {
+		for x, to := range state.Goto {
+			c.States[mapping[state.Index]].Goto[x] = mapping[to]
+		}
+	}
+	return c
+}
+
+// build 从初始核心项目出发 广度优先构造项目集族 closure 决定项目的种类
+func build(g *Grammar, init []Item, closure func([]Item) Set) *Collection {
+	c := &Collection{Grammar: g}
+	index := make(map[string]int)
+	add := func(s Set) int {
+		if i, ok := index[s.Key()]; ok {
+			return i
+		}
+		state := &State{Index: len(c.States), Items: s, Goto: make(map[string]int)}
+		index[s.Key()] = state.Index
+		c.States = append(c.States, state)
+		return state.Index
+	}
+	add(closure(init))
+	for k := 0; k < len(c.States); k++ {
+		state := c.States[k]
+		for _, x := range g.Symbols(state.Items) {
+			state.Goto[x] = add(closure(g.Goto(state.Items, x)))
+		}
+	}
+	return c
+}
+
+// String 输出全部项目集和转移
+func (c *Collection) String() string {
+	var build strings.Builder
+	for _, state := range c.States {
+		if len(state.Merged) > 1 {
+			var merged []string
+			for _, m := range state.Merged {
+				merged = append(merged, fmt.Sprintf("I%d", m))
+			}
+			build.WriteString(fmt.Sprintf("I%d: (%s)\n", state.Index, strings.Join(merged, " ")))
+		} else {
+			build.WriteString(fmt.Sprintf("I%d:\n", state.Index))
+		}
+		for _, i := range state.Items {
+			build.WriteString("  " + c.Grammar.ItemString(i) + "\n")
+		}
+		for _, x := range c.Grammar.Symbols(state.Items) {
+			build.WriteString(fmt.Sprintf("  GOTO(I%d, %s) = I%d\n", state.Index, c.Grammar.Name(x), state.Goto[x]))
+		}
+	}
+	return build.String()
+}
@@ -0,0 +1,245 @@
+/*
+Package item LR 项目集处理包 增广文法 项目 闭包 GOTO 以及规范项目集族
+
+LR(0) 项目和带向前看符号的 LR(1) 项目使用同一个 Item 结构 LR(0) 项目的 Lookahead 为空
+*/
+package item
+
+import (
+	"fmt"
+	"github.com/esonhugh/compiler/grammarLL1/first"
+	"github.com/esonhugh/compiler/grammarLL1/rule"
+	"github.com/esonhugh/compiler/grammarLL1/util"
+	"github.com/esonhugh/compiler/util/transfer"
+	"sort"
+	"strings"
+)
+
+// EndToken 句子结束符号
+const EndToken = "#"
+
+// Grammar 增广文法 第 0 个产生式为 S'->S
+type Grammar struct {
+	Rules        *rule.Rule
+	Start        string            // Start 增广后的开始符号 S'
+	Origin       string            // Origin 原来的开始符号 S
+	Formulas     []*rule.Formula   // Formulas 按声明顺序编号的产生式
+	Terminals    []string          // Terminals 排序后的终结符 最后是 #
+	Nonterminals []string          // Nonterminals 按声明顺序的非终结符 不含 S'
+	Names        map[string]string // Names 文法的显示名 加上增广开始符号 S' 的名字
+	First        first.FirstSet    // First 原文法的 FIRST 集 用于 LR(1) 闭包
+	Matcher      *rule.Matcher     // Matcher 词法单元与终结符的对应关系
+}
+
+// Augment 构造增广文法 S' 用一个空闲的大写字母表示
+func Augment(r *rule.Rule, start string) (*Grammar, error) {
+	s, err := r.NewNonterminal()
+	if err != nil {
+		return nil, err
+	}
+	g := &Grammar{
+		Rules:        r,
+		Start:        s,
+		Origin:       start,
+		Formulas:     []*rule.Formula{{Left: s, Right: start}},
+		Nonterminals: r.Nonterminals(),
+		Names:        map[string]string{s: r.Name(start) + "'"},
+		First:        first.GetFirstSet(r),
+		Matcher:      r.Matcher(),
+	}
+	for key, value := range r.Names {
+		g.Names[key] = value
+	}
+	g.Formulas = append(g.Formulas, r.Formulas()...)
+	g.Terminals = r.Symbols().Terminals
+	return g, nil
+}
+
+// Right 第 i 个产生式的右部 空产生式返回空串
+func (g *Grammar) Right(i int) string {
+	if g.Formulas[i].Right == "&" {
+		return ""
+	}
+	return g.Formulas[i].Right
+}
+
+// Name 符号串的显示形式 使用文法的显示名以及 S' 的名字
+func (g *Grammar) Name(s string) string {
+	return transfer.TransferWith(s, g.Names)
+}
+
+// FormulaString 第 i 个产生式的显示形式
+func (g *Grammar) FormulaString(i int) string {
+	return fmt.Sprintf("%s->%s", g.Name(g.Formulas[i].Left), g.Name(g.Formulas[i].Right))
+}
+
+// Item LR 项目 A->α·β 以及可选的向前看符号
+type Item struct {
+	Formula   int    // Formula 产生式编号
+	Dot       int    // Dot 圆点的位置
+	Lookahead string // Lookahead LR(1) 向前看符号 LR(0) 项目为空
+}
+
+// Core 去掉向前看符号的核心项目
+func (i Item) Core() Item {
+	return Item{Formula: i.Formula, Dot: i.Dot}
+}
+
+// Next 圆点后面的符号 圆点在最后时返回空串
+func (g *Grammar) Next(i Item) string {
+	right := g.Right(i.Formula)
+	if i.Dot >= len(right) {
+		return ""
+	}
+	return string(right[i.Dot])
+}
+
+// IsReduce 圆点在最后 是归约项目
+func (g *Grammar) IsReduce(i Item) bool {
+	return i.Dot >= len(g.Right(i.Formula))
+}
+
+// ItemString 项目的显示形式 A->α·β, a
+func (g *Grammar) ItemString(i Item) string {
+	right := g.Right(i.Formula)
+	s := fmt.Sprintf("%s->%s·%s", g.Name(g.Formulas[i.Formula].Left), g.Name(right[:i.Dot]), g.Name(right[i.Dot:]))
+	if i.Lookahead != "" {
+		s += ", " + i.Lookahead
+	}
+	return s
+}
+
+// Set 项目集 按产生式编号 圆点位置 向前看符号排序 可以直接比较
+type Set []Item
+
+// NewSet 去重排序得到项目集
+func NewSet(items []Item) Set {
+	seen := make(map[Item]bool)
+	var s Set
+	for _, i := range items {
+		if !seen[i] {
+			seen[i] = true
+			s = append(s, i)
+		}
+	}
+	sort.Slice(s, func(a, b int) bool {
+		if s[a].Formula != s[b].Formula {
+			return s[a].Formula < s[b].Formula
+		}
+		if s[a].Dot != s[b].Dot {
+			return s[a].Dot < s[b].Dot
+		}
+		return s[a].Lookahead < s[b].Lookahead
+	})
+	return s
+}
+
+// Key 项目集的唯一标识 用于判断两个项目集是否相同
+func (s Set) Key() string {
+	var build strings.Builder
+	for _, i := range s {
+		build.WriteString(fmt.Sprintf("%d.%d.%s;", i.Formula, i.Dot, i.Lookahead))
+	}
+	return build.String()
+}
+
+// CoreKey 项目集核心的唯一标识 LALR 按核心合并状态
+func (s Set) CoreKey() string {
+	var cores []Item
+	for _, i := range s {
+		cores = append(cores, i.Core())
+	}
+	return NewSet(cores).Key()
+}
+
+// Closure LR(0) 项目集闭包 A->α·Bβ 时加入所有 B->·γ
+func (g *Grammar) Closure(items []Item) Set {
+	res := append([]Item(nil), items...)
+	added := make(map[Item]bool)
+	for _, i := range items {
+		added[i] = true
+	}
+	for k := 0; k < len(res); k++ {
+		next := g.Next(res[k])
+		if next == "" || util.IsTerminal(next[0]) {
+			continue
+		}
+		for f := range g.Formulas {
+			if g.Formulas[f].Left != next {
+				continue
+			}
+			i := Item{Formula: f}
+			if !added[i] {
+				added[i] = true
+				res = append(res, i)
+			}
+		}
+	}
+	return NewSet(res)
+}
+
+// Closure1 LR(1) 项目集闭包
+// [A->α·Bβ, a] 时对 FIRST(βa) 中的每个终结符 b 加入所有 [B->·γ, b]
+func (g *Grammar) Closure1(items []Item) Set {
+	res := append([]Item(nil), items...)
+	added := make(map[Item]bool)
+	for _, i := range items {
+		added[i] = true
+	}
+	for k := 0; k < len(res); k++ {
+		next := g.Next(res[k])
+		if next == "" || util.IsTerminal(next[0]) {
+			continue
+		}
+		beta := g.Right(res[k].Formula)[res[k].Dot+1:]
+		lookaheads := g.First.FirstOf(beta)
+		if _, ok := lookaheads["&"]; ok {
+			delete(lookaheads, "&")
+			lookaheads[res[k].Lookahead] = struct{}{}
+		}
+		for f := range g.Formulas {
+			if g.Formulas[f].Left != next {
+				continue
+			}
+			for _, b := range g.Terminals {
+				if _, ok := lookaheads[b]; !ok {
+					continue
+				}
+				i := Item{Formula: f, Lookahead: b}
+				if !added[i] {
+					added[i] = true
+					res = append(res, i)
+				}
+			}
+		}
+	}
+	return NewSet(res)
+}
+
+// Goto 项目集 s 读入符号 x 后的核心项目 还没有求闭包
+func (g *Grammar) Goto(s Set, x string) []Item {
+	var res []Item
+	for _, i := range s {
+		if g.Next(i) == x {
+			res = append(res, Item{Formula: i.Formula, Dot: i.Dot + 1, Lookahead: i.Lookahead})
+		}
+	}
+	return res
+}
+
+// Symbols 项目集中圆点后出现的符号 按文法符号顺序排列
+func (g *Grammar) Symbols(s Set) []string {
+	seen := make(map[string]bool)
+	for _, i := range s {
+		if next := g.Next(i); next != "" {
+			seen[next] = true
+		}
+	}
+	var res []string
+	for _, x := range append(append([]string(nil), g.Terminals...), g.Nonterminals...) {
+		if seen[x] {
+			res = append(res, x)
+		}
+	}
+	return res
+}
@@ -0,0 +1,66 @@
+package grammarLR
+
+import (
+	"github.com/esonhugh/compiler/lexer"
+	"testing"
+)
+
+// 二义的表达式文法 用优先级和结合性解决冲突 u 为一元负号
+const ambiguous = "%left + -\n%left * /\n%right ^\n%right u\nE->E+E|E-E|E*E|E/E|E^E|(E)|i|-E %prec u"
+
+func parseTree(t *testing.T, rules, code string) string {
+	table, _, err := BuildTable(rules, "E", LALR1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewGrammar(lexer.Analyse(code), table)
+	if _, ok := g.Analyze(); !ok {
+		return ""
+	}
+	return g.Tree.String()
+}
+
+func TestPrecedenceResolution(t *testing.T) {
+	table, _, err := BuildTable(ambiguous, "E", SLR1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(table.PrecedenceReport())
+	if !table.IsConflictFree() || len(table.Resolved) == 0 {
+		t.Fatal("all conflicts should be resolved by precedence")
+	}
+	cases := map[string]string{
+		"a+b*c+d": "E(E(E(i) + E(E(i) * E(i))) + E(i))",
+		"a-b-c":   "E(E(E(i) - E(i)) - E(i))",
+		"a^b^c":   "E(E(i) ^ E(E(i) ^ E(i)))",
+		"-a*b":    "E(E(- E(i)) * E(i))",
+		"(a+b)*c": "E(E(( E(E(i) + E(i)) )) * E(i))",
+	}
+	for code, want := range cases {
+		if got := parseTree(t, ambiguous, code); got != want {
+			t.Errorf("%s: got %s, want %s", code, got, want)
+		}
+	}
+}
+
+func TestNonAssoc(t *testing.T) {
+	rules := "%nonassoc <\n%left +\nE->E<E|E+E|i"
+	if parseTree(t, rules, "a<b+c") == "" {
+		t.Error("a<b+c should be accepted")
+	}
+	if parseTree(t, rules, "a<b<c") != "" {
+		t.Error("a<b<c should be rejected by %nonassoc")
+	}
+}
+
+func TestUnresolvedConflict(t *testing.T) {
+	// 只声明了 + 的优先级 * 相关的冲突仍然存在
+	table, _, err := BuildTable("%left +\nE->E+E|E*E|i", "E", SLR1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(table.PrecedenceReport())
+	if len(table.Resolved) != 1 || len(table.Conflicts) != 3 {
+		t.Fatalf("expect 1 resolved and 3 remaining conflicts, got %d %d", len(table.Resolved), len(table.Conflicts))
+	}
+}
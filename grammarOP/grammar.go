@@ -0,0 +1,217 @@
+package grammarOP
+
+import (
+	"errors"
+	"fmt"
+	"github.com/esonhugh/compiler/grammarLL1/rule"
+	"github.com/esonhugh/compiler/lexer"
+	"github.com/esonhugh/compiler/tree"
+	"github.com/gookit/color"
+	"github.com/liushuochen/gotable"
+	"strconv"
+	"strings"
+)
+
+// Reduction 一次归约 归约的素短语和与之匹配的产生式
+type Reduction struct {
+	Phrase  string
+	Formula *rule.Formula
+}
+
+// Step 分析过程中的一步 符号栈 栈顶终结符与当前输入的关系 剩余输入和动作
+type Step struct {
+	Stack    string
+	Relation string
+	Input    string
+	Action   string
+	Phrase   string
+}
+
+// Grammar 算符优先分析器
+type Grammar struct {
+	table   *Table
+	matcher *rule.Matcher
+	tokens  []*lexer.Token
+	pos     int
+	stack   []string
+	nodes   []*tree.Node
+	Steps   []*Step
+	Tree    *tree.Node
+}
+
+// Analyze 构造优先关系表并分析 返回按顺序的归约
+func Analyze(raw []*lexer.Token, rules string, start string) ([]*Reduction, bool) {
+	r := rule.NewRules()
+	if err := r.AddRules(rules); err != nil {
+		color.Redln(err.Error())
+		return nil, false
+	}
+	table, err := GetTable(r, start)
+	if err != nil {
+		color.Redln(err.Error())
+		return nil, false
+	}
+	fmt.Print(table.FirstVT.String("FIRSTVT", r))
+	fmt.Print(table.LastVT.String("LASTVT", r))
+	fmt.Println(table.String())
+	if !table.IsOperatorPrecedence() {
+		color.Redln(table.ConflictString())
+		return nil, false
+	}
+	g := NewGrammar(raw, table)
+	res, err := g.Analyze()
+	fmt.Println(g.TraceString())
+	if err != nil {
+		color.Redln(err.Error())
+		return res, false
+	}
+	return res, true
+}
+
+// NewGrammar 创建一个新的算符优先分析器
+func NewGrammar(token []*lexer.Token, table *Table) *Grammar {
+	tokens := append([]*lexer.Token(nil), token...)
+	// 结束符的位置在最后一个词法单元之后 用于报告输入提前结束的错误
+	end := &lexer.Token{
+		Typ:    lexer.END,
+		Value:  EndToken,
+		Row:    1,
+		Column: 1,
+	}
+	if len(token) > 0 {
+		last := token[len(token)-1]
+		end.Row, end.Column = last.Row, last.Column+len(last.Value)
+	}
+	tokens = append(tokens, end)
+	return &Grammar{
+		table:   table,
+		matcher: table.rules.Matcher(),
+		tokens:  tokens,
+		stack:   []string{EndToken},
+		nodes:   []*tree.Node{nil},
+	}
+}
+
+// Analyze 分析 栈顶终结符 ·> 当前输入时归约最左素短语 否则移进
+// 返回按顺序的归约 同时记录每一步和语法树
+func (g *Grammar) Analyze() (res []*Reduction, err error) {
+	for {
+		current := g.matcher.TerminalOf(g.tokens[g.pos])
+		k := g.topTerminal(len(g.stack) - 1)
+		relation := g.table.Relation(g.stack[k], current)
+		if g.stack[k] == EndToken && current == EndToken {
+			g.record(relation, "accept", "")
+			if len(g.stack) != 2 {
+				return res, errors.New("input is not a sentence")
+			}
+			g.Tree = g.nodes[1]
+			return res, nil
+		}
+		switch relation {
+		case Less, Equal:
+			g.record(relation, "shift", "")
+			g.stack = append(g.stack, current)
+			g.nodes = append(g.nodes, tree.Leaf(current, g.tokens[g.pos]))
+			g.pos++
+		case Greater:
+			// 向左找到 <· 的位置 之间的部分就是最左素短语
+			j := k
+			for {
+				q := g.stack[j]
+				j = g.topTerminal(j - 1)
+				if g.table.Relation(g.stack[j], q) == Less {
+					break
+				}
+			}
+			phrase := strings.Join(g.stack[j+1:], "")
+			formula := g.match(g.stack[j+1:])
+			if formula == nil {
+				g.record(relation, "error", phrase)
+				return res, g.errorf("no production matches prime phrase %s", g.table.rules.Name(phrase))
+			}
+			g.record(relation, "reduce "+g.table.rules.Name(formula.Left)+"->"+g.table.rules.Name(formula.Right), phrase)
+			node := tree.Reduce(formula.Left, g.nodes[j+1:])
+			g.stack = append(g.stack[:j+1], formula.Left)
+			g.nodes = append(g.nodes[:j+1], node)
+			res = append(res, &Reduction{Phrase: phrase, Formula: formula})
+		default:
+			g.record(relation, "error", "")
+			return res, g.errorf("no relation between %s and %s", g.stack[k], current)
+		}
+	}
+}
+
+// errorf 在当前词法单元的位置报告错误 line 1 col 5: ...
+func (g *Grammar) errorf(format string, args ...interface{}) error {
+	t := g.tokens[g.pos]
+	return fmt.Errorf("line %d col %d: %s", t.Row, t.Column, fmt.Sprintf(format, args...))
+}
+
+// topTerminal 从 i 向下找到最近的终结符的位置
+func (g *Grammar) topTerminal(i int) int {
+	for isNonterminal(g.table.rules, g.stack[i]) {
+		i--
+	}
+	return i
+}
+
+// match 找到右部与素短语匹配的产生式 终结符必须相同 非终结符的位置只要求也是非终结符
+func (g *Grammar) match(phrase []string) *rule.Formula {
+	for _, formula := range g.table.rules.Formulas() {
+		if len(formula.Right) != len(phrase) {
+			continue
+		}
+		ok := true
+		for i, x := range phrase {
+			y := formula.Right[i : i+1]
+			if isNonterminal(g.table.rules, x) != isNonterminal(g.table.rules, y) ||
+				!isNonterminal(g.table.rules, x) && x != y {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return formula
+		}
+	}
+	return nil
+}
+
+// record 记录当前的符号栈 关系 剩余输入和将要执行的动作
+func (g *Grammar) record(relation, action, phrase string) {
+	var input strings.Builder
+	for _, t := range g.tokens[g.pos:] {
+		input.WriteString(t.Value)
+	}
+	g.Steps = append(g.Steps, &Step{
+		Stack:    strings.Join(g.stack, ""),
+		Relation: relation,
+		Input:    input.String(),
+		Action:   action,
+		Phrase:   phrase,
+	})
+}
+
+// TraceString 以表格输出分析过程
+func (g *Grammar) TraceString() string {
+	table, err := gotable.Create("步骤", "符号栈", "关系", "剩余输入", "素短语", "动作")
+	if err != nil {
+		fmt.Println(err.Error())
+		return ""
+	}
+	for i, step := range g.Steps {
+		err = table.AddRow(map[string]string{
+			"步骤":   strconv.Itoa(i + 1),
+			"符号栈":  g.table.rules.Name(step.Stack),
+			"关系":   step.Relation,
+			"剩余输入": step.Input,
+			"素短语":  g.table.rules.Name(step.Phrase),
+			"动作":   step.Action,
+		})
+		if err != nil {
+			fmt.Println(err.Error())
+			return ""
+		}
+	}
+	return table.String()
+}
@@ -0,0 +1,129 @@
+package grammarOP
+
+import (
+	"github.com/esonhugh/compiler/grammarLL1/rule"
+	"github.com/esonhugh/compiler/lexer"
+	"strings"
+	"testing"
+)
+
+const expression = "E->E+T|T\nT->T*F|F\nF->(E)|i"
+
+func TestVT(t *testing.T) {
+	r := rule.MustParse(expression)
+	firstVT, lastVT := GetFirstVT(r), GetLastVT(r)
+	t.Log(firstVT.String("FIRSTVT", r), lastVT.String("LASTVT", r))
+	want := map[string][2]string{
+		"E": {"( * + i", ") * + i"},
+		"T": {"( * i", ") * i"},
+		"F": {"( i", ") i"},
+	}
+	for left, sets := range want {
+		if got := strings.Join(sortedKeys(firstVT[left]), " "); got != sets[0] {
+			t.Errorf("FIRSTVT(%s) = %s, want %s", left, got, sets[0])
+		}
+		if got := strings.Join(sortedKeys(lastVT[left]), " "); got != sets[1] {
+			t.Errorf("LASTVT(%s) = %s, want %s", left, got, sets[1])
+		}
+	}
+}
+
+func TestTable(t *testing.T) {
+	table, err := GetTable(rule.MustParse(expression), "E")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(table.String())
+	if !table.IsOperatorPrecedence() {
+		t.Fatalf("unexpected conflicts\n%s", table.ConflictString())
+	}
+	cases := []struct{ a, b, relation string }{
+		{"+", "+", Greater}, {"+", "*", Less}, {"*", "+", Greater}, {"*", "*", Greater},
+		{"(", ")", Equal}, {"(", "i", Less}, {"i", ")", Greater}, {")", "(", ""},
+		{"#", "#", Equal}, {"#", "(", Less}, {"+", "#", Greater}, {"i", "i", ""},
+	}
+	for _, c := range cases {
+		if got := table.Relation(c.a, c.b); got != c.relation {
+			t.Errorf("%s %s: got %q, want %q", c.a, c.b, got, c.relation)
+		}
+	}
+}
+
+func TestNotOperator(t *testing.T) {
+	if _, err := GetTable(rule.MustParse("E->EAE|i\nA->+|*"), "E"); err == nil {
+		t.Error("adjacent nonterminals should be rejected")
+	}
+	if _, err := GetTable(rule.MustParse("E->E+i|&"), "E"); err == nil {
+		t.Error("empty production should be rejected")
+	}
+}
+
+func TestConflict(t *testing.T) {
+	// 二义的表达式文法 + 与 + 之间既有 <· 也有 ·>
+	table, err := GetTable(rule.MustParse("E->E+E|E*E|i"), "E")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Log(table.ConflictString())
+	if table.IsOperatorPrecedence() {
+		t.Fatal("E->E+E|E*E|i is not an operator precedence grammar")
+	}
+	found := false
+	for _, c := range table.Conflicts {
+		if c.Left == "+" && c.Right == "+" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("expected a conflict between + and +\n%s", table.ConflictString())
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	table, _ := GetTable(rule.MustParse(expression), "E")
+	g := NewGrammar(lexer.Analyse("a+b*(c+d)"), table)
+	res, err := g.Analyze()
+	t.Log(g.TraceString())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var phrases []string
+	for _, p := range res {
+		phrases = append(phrases, p.Phrase)
+	}
+	if got, want := strings.Join(phrases, " "), "i i i i F+F (E) F*F F+T"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	if g.Tree.String() != "E(F(i) + T(F(i) * F(( E(F(i) + F(i)) ))))" {
+		t.Errorf("unexpected tree %s", g.Tree.String())
+	}
+	errs := map[string]string{
+		"a+":   "line 1 col 3: no production matches prime phrase F+",
+		"a b":  "line 1 col 3: no relation between i and i",
+		"(a+b": "line 1 col 5: no relation between ( and #",
+		"a+*b": "line 1 col 5: no production matches prime phrase *F",
+	}
+	for code, want := range errs {
+		if _, err := NewGrammar(lexer.Analyse(code), table).Analyze(); err == nil || err.Error() != want {
+			t.Errorf("%s: got %v, want %s", code, err, want)
+		}
+	}
+}
+
+func TestNames(t *testing.T) {
+	// 没有 %name 声明时 S 按原样输出
+	r := rule.MustParse("E->E+S|S\nS->i")
+	table, _ := GetTable(r, "E")
+	if got := table.FirstVT.String("FIRSTVT", r); got != "FIRSTVT(E) = { +, i }\nFIRSTVT(S) = { i }\n" {
+		t.Errorf("got\n%s", got)
+	}
+	r = rule.MustParse("%name S T'\nE->E+S|S\nS->i")
+	table, _ = GetTable(r, "E")
+	g := NewGrammar(lexer.Analyse("a+b"), table)
+	if _, err := g.Analyze(); err != nil {
+		t.Fatal(err)
+	}
+	if g.Steps[1].Action != "reduce T'->i" || !strings.Contains(g.TraceString(), "#T'+T'") {
+		t.Errorf("unexpected trace\n%s", g.TraceString())
+	}
+}
@@ -0,0 +1,179 @@
+package grammarOP
+
+import (
+	"errors"
+	"fmt"
+	"github.com/esonhugh/compiler/grammarLL1/rule"
+	"github.com/liushuochen/gotable"
+	"strings"
+)
+
+// 优先关系
+const (
+	Less    = "<"
+	Equal   = "="
+	Greater = ">"
+)
+
+// Conflict 一对终结符之间有多个优先关系
+type Conflict struct {
+	Left      string
+	Right     string
+	Relations []string
+}
+
+// Table 算符优先关系表 Cells[a][b] 为 a 与 b 之间的关系
+type Table struct {
+	Start     string
+	Terminals []string
+	Cells     map[string]map[string]string
+	Conflicts []*Conflict
+	FirstVT   VTSet
+	LastVT    VTSet
+	rules     *rule.Rule
+}
+
+// NotOperator 返回不满足算符文法要求的产生式
+// 算符文法的右部不能是空串 也不能有两个相邻的非终结符
+func NotOperator(r *rule.Rule) []*rule.Formula {
+	var res []*rule.Formula
+	for _, formula := range r.Formulas() {
+		if formula.Right == "&" || formula.Right == "" {
+			res = append(res, formula)
+			continue
+		}
+		for i := 1; i < len(formula.Right); i++ {
+			if isNonterminal(r, formula.Right[i-1:i]) && isNonterminal(r, formula.Right[i:i+1]) {
+				res = append(res, formula)
+				break
+			}
+		}
+	}
+	return res
+}
+
+// GetTable 根据规则构造优先关系表 文法不是算符文法时返回错误
+// 关系冲突记录在 Conflicts 中 表中保留先得到的关系
+func GetTable(r *rule.Rule, start string) (*Table, error) {
+	if _, ok := r.Rules[start]; !ok {
+		return nil, errors.New("start symbol " + start + " has no production")
+	}
+	if bad := NotOperator(r); len(bad) > 0 {
+		var formulas []string
+		for _, f := range bad {
+			formulas = append(formulas, r.Name(f.Left)+"->"+r.Name(f.Right))
+		}
+		return nil, errors.New("not an operator grammar: " + strings.Join(formulas, ", "))
+	}
+	t := &Table{
+		Start:   start,
+		Cells:   make(map[string]map[string]string),
+		FirstVT: GetFirstVT(r),
+		LastVT:  GetLastVT(r),
+		rules:   r,
+	}
+	seen := make(map[string]struct{})
+	for _, formula := range r.Formulas() {
+		for i := 0; i < len(formula.Right); i++ {
+			x := formula.Right[i : i+1]
+			if _, ok := seen[x]; !ok && !isNonterminal(r, x) {
+				seen[x] = struct{}{}
+				t.Terminals = append(t.Terminals, x)
+			}
+		}
+	}
+	t.Terminals = append(t.Terminals, EndToken)
+	for _, a := range t.Terminals {
+		t.Cells[a] = make(map[string]string)
+	}
+
+	// 把开始符号看作 #S#
+	augmented := append(r.Formulas(), &rule.Formula{Right: EndToken + start + EndToken})
+	for _, formula := range augmented {
+		right := formula.Right
+		for i := 0; i+1 < len(right); i++ {
+			x, y := right[i:i+1], right[i+1:i+2]
+			switch {
+			case !isNonterminal(r, x) && !isNonterminal(r, y):
+				t.set(x, y, Equal)
+			case !isNonterminal(r, x):
+				for b := range t.FirstVT[y] {
+					t.set(x, b, Less)
+				}
+				if i+2 < len(right) && !isNonterminal(r, right[i+2:i+3]) {
+					t.set(x, right[i+2:i+3], Equal)
+				}
+			default:
+				for a := range t.LastVT[x] {
+					t.set(a, y, Greater)
+				}
+			}
+		}
+	}
+	return t, nil
+}
+
+// set 登记 a 与 b 的关系 已有不同的关系时记为冲突
+func (t *Table) set(a, b, relation string) {
+	old, ok := t.Cells[a][b]
+	if !ok {
+		t.Cells[a][b] = relation
+		return
+	}
+	if old == relation {
+		return
+	}
+	for _, c := range t.Conflicts {
+		if c.Left == a && c.Right == b {
+			for _, r := range c.Relations {
+				if r == relation {
+					return
+				}
+			}
+			c.Relations = append(c.Relations, relation)
+			return
+		}
+	}
+	t.Conflicts = append(t.Conflicts, &Conflict{Left: a, Right: b, Relations: []string{old, relation}})
+}
+
+// Relation a 与 b 之间的关系 没有关系时返回空串
+func (t *Table) Relation(a, b string) string {
+	return t.Cells[a][b]
+}
+
+// IsOperatorPrecedence 是否是算符优先文法 即任意两个终结符之间至多有一种关系
+func (t *Table) IsOperatorPrecedence() bool {
+	return len(t.Conflicts) == 0
+}
+
+// ConflictString 输出全部关系冲突
+func (t *Table) ConflictString() string {
+	var build strings.Builder
+	for _, c := range t.Conflicts {
+		build.WriteString(fmt.Sprintf("conflict between %s and %s: %s\n", c.Left, c.Right, strings.Join(c.Relations, " ")))
+	}
+	return build.String()
+}
+
+// String 输出优先关系表 与 analysisTable.SymbolTable 一样使用 gotable
+func (t *Table) String() string {
+	column := append([]string{" "}, t.Terminals...)
+	table, err := gotable.Create(column...)
+	if err != nil {
+		fmt.Println(err.Error())
+		return ""
+	}
+	for _, a := range t.Terminals {
+		row := make(map[string]string)
+		row[" "] = a
+		for _, b := range t.Terminals {
+			row[b] = t.Cells[a][b]
+		}
+		if err = table.AddRow(row); err != nil {
+			fmt.Println(err.Error())
+			return ""
+		}
+	}
+	return table.String()
+}
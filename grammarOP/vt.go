@@ -0,0 +1,103 @@
+/*
+Package grammarOP 算符优先分析包
+
+根据算符文法计算 FIRSTVT 和 LASTVT 集 构造 <· =· ·> 优先关系表 并按素短语进行移进归约分析
+*/
+package grammarOP
+
+import (
+	"github.com/esonhugh/compiler/grammarLL1/rule"
+	"sort"
+	"strings"
+)
+
+// EndToken 句子结束符号 #S#
+const EndToken = "#"
+
+// VTSet FIRSTVT 或者 LASTVT 集 非终结符到终结符集合
+type VTSet map[string]map[string]struct{}
+
+// GetFirstVT 计算 FIRSTVT 集
+//
+//	P->a... 或 P->Qa... 则 a 属于 FIRSTVT(P)
+//	P->Q... 则 FIRSTVT(Q) 包含于 FIRSTVT(P)
+func GetFirstVT(r *rule.Rule) VTSet {
+	return getVT(r, func(right string) string { return right })
+}
+
+// GetLastVT 计算 LASTVT 集 与 FIRSTVT 对称 从右部的末尾看起
+//
+//	P->...a 或 P->...aQ 则 a 属于 LASTVT(P)
+//	P->...Q 则 LASTVT(Q) 包含于 LASTVT(P)
+func GetLastVT(r *rule.Rule) VTSet {
+	return getVT(r, reverse)
+}
+
+// getVT 按 order 给出的方向看产生式右部的前两个符号 求不动点
+func getVT(r *rule.Rule, order func(string) string) VTSet {
+	set := make(VTSet)
+	for _, left := range r.Nonterminals() {
+		set[left] = make(map[string]struct{})
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, formula := range r.Formulas() {
+			right := order(formula.Right)
+			if right == "&" {
+				continue
+			}
+			add := func(x string) {
+				if _, ok := set[formula.Left][x]; !ok {
+					set[formula.Left][x] = struct{}{}
+					changed = true
+				}
+			}
+			first := right[:1]
+			if !isNonterminal(r, first) {
+				add(first)
+				continue
+			}
+			if len(right) > 1 && !isNonterminal(r, right[1:2]) {
+				add(right[1:2])
+			}
+			for x := range set[first] {
+				add(x)
+			}
+		}
+	}
+	return set
+}
+
+// String 按非终结符的声明顺序输出 FIRSTVT(E) = { (, *, +, i }
+func (s VTSet) String(name string, r *rule.Rule) string {
+	var build strings.Builder
+	for _, left := range r.Nonterminals() {
+		build.WriteString(name + "(" + r.Name(left) + ") = { ")
+		build.WriteString(strings.Join(sortedKeys(s[left]), ", "))
+		build.WriteString(" }\n")
+	}
+	return build.String()
+}
+
+// isNonterminal 符号是否是非终结符
+func isNonterminal(r *rule.Rule, s string) bool {
+	_, ok := r.Rules[s]
+	return ok
+}
+
+func reverse(s string) string {
+	b := []byte(s)
+	for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+		b[i], b[j] = b[j], b[i]
+	}
+	return string(b)
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	res := make([]string, 0, len(m))
+	for key := range m {
+		res = append(res, key)
+	}
+	sort.Strings(res)
+	return res
+}
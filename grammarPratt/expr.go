@@ -0,0 +1,58 @@
+package grammarPratt
+
+import (
+	"github.com/esonhugh/compiler/lexer"
+	"github.com/esonhugh/compiler/tree"
+	"strings"
+)
+
+// 表达式结点的种类
+const (
+	Primary = "primary"
+	Unary   = "unary"
+	Binary  = "binary"
+	Postfix = "postfix"
+	Call    = "call"
+)
+
+// Expr 表达式树结点
+// Primary 结点的 Token 为变量或者常量 其他结点的 Token 为运算符 调用结点的 Token 为函数名
+type Expr struct {
+	Kind     string
+	Token    *lexer.Token
+	Operands []*Expr
+}
+
+// String 前缀形式 (+ a (* b c))
+func (e *Expr) String() string {
+	if e.Kind == Primary {
+		return e.Token.Value
+	}
+	var build strings.Builder
+	build.WriteString("(")
+	build.WriteString(e.Token.Value)
+	if e.Kind == Postfix {
+		build.WriteString(" post")
+	}
+	if e.Kind == Call {
+		build.WriteString(" call")
+	}
+	for _, o := range e.Operands {
+		build.WriteString(" ")
+		build.WriteString(o.String())
+	}
+	build.WriteString(")")
+	return build.String()
+}
+
+// Tree 转换为语法树 运算符作为内部结点 便于和其他分析器的结果一起输出
+func (e *Expr) Tree() *tree.Node {
+	if e.Kind == Primary {
+		return tree.Leaf(e.Token.Value, e.Token)
+	}
+	n := &tree.Node{Symbol: e.Token.Value, Token: e.Token}
+	for _, o := range e.Operands {
+		n.Children = append(n.Children, o.Tree())
+	}
+	return n
+}
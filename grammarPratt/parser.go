@@ -0,0 +1,196 @@
+package grammarPratt
+
+import (
+	"fmt"
+	"github.com/esonhugh/compiler/lexer"
+)
+
+// Cursor 词法单元序列和当前位置 递归下降的语句分析器和表达式分析器共用一个 Cursor
+type Cursor struct {
+	Tokens []*lexer.Token
+	Pos    int
+}
+
+// NewCursor 创建一个 Cursor 跳过注释和结束符
+func NewCursor(tokens []*lexer.Token) *Cursor {
+	c := &Cursor{}
+	for _, t := range tokens {
+		if t.Typ != lexer.COMMENT && t.Typ != lexer.END {
+			c.Tokens = append(c.Tokens, t)
+		}
+	}
+	return c
+}
+
+// Peek 当前的词法单元 没有时返回 nil
+func (c *Cursor) Peek() *lexer.Token {
+	if c.Pos < len(c.Tokens) {
+		return c.Tokens[c.Pos]
+	}
+	return nil
+}
+
+// Next 取出当前的词法单元
+func (c *Cursor) Next() *lexer.Token {
+	t := c.Peek()
+	if t != nil {
+		c.Pos++
+	}
+	return t
+}
+
+// Is 当前的词法单元的值是否是 value
+func (c *Cursor) Is(value string) bool {
+	t := c.Peek()
+	return t != nil && t.Value == value && t.Typ != lexer.STRING
+}
+
+// Expect 当前的词法单元必须是 value 并且取出
+func (c *Cursor) Expect(value string) (*lexer.Token, error) {
+	if !c.Is(value) {
+		return nil, c.Errorf("expected '%s'", value)
+	}
+	return c.Next(), nil
+}
+
+// Errorf 带有当前位置的错误 line 1 col 5: expected ')', found 'b'
+// 输入提前结束时位置在最后一个词法单元之后
+func (c *Cursor) Errorf(format string, args ...interface{}) error {
+	found, row, column := "end of input", 1, 1
+	if t := c.Peek(); t != nil {
+		found, row, column = "'"+t.Value+"'", t.Row, t.Column
+	} else if len(c.Tokens) > 0 {
+		last := c.Tokens[len(c.Tokens)-1]
+		row, column = last.Row, last.Column+len(last.Value)
+	}
+	return fmt.Errorf("line %d col %d: %s, found %s", row, column, fmt.Sprintf(format, args...), found)
+}
+
+// Parser Pratt 表达式分析器
+type Parser struct {
+	*Cursor
+	table *Table
+}
+
+// Parse 把全部词法单元作为一个表达式分析
+func Parse(tokens []*lexer.Token, table *Table) (*Expr, error) {
+	p := NewParser(NewCursor(tokens), table)
+	e, err := p.Expression()
+	if err != nil {
+		return nil, err
+	}
+	if p.Peek() != nil {
+		return nil, p.Errorf("expected operator")
+	}
+	return e, nil
+}
+
+// NewParser 在 Cursor 上创建分析器 分析完一个表达式后 Cursor 停在表达式后面的词法单元上
+func NewParser(c *Cursor, table *Table) *Parser {
+	return &Parser{Cursor: c, table: table}
+}
+
+// Expression 分析一个完整的表达式 遇到不是运算符的词法单元时停止
+func (p *Parser) Expression() (*Expr, error) {
+	return p.expression(0)
+}
+
+// expression 分析绑定强度大于 minBP 的表达式
+func (p *Parser) expression(minBP int) (*Expr, error) {
+	left, err := p.prefix()
+	if err != nil {
+		return nil, err
+	}
+	last := ""
+	for {
+		t := p.Peek()
+		if t == nil || t.Typ != lexer.OPERATOR {
+			return left, nil
+		}
+		if bp, ok := p.table.postfix[t.Value]; ok {
+			if bp < minBP {
+				return left, nil
+			}
+			p.Next()
+			left = &Expr{Kind: Postfix, Token: t, Operands: []*Expr{left}}
+			continue
+		}
+		b, ok := p.table.infix[t.Value]
+		if !ok || b.Left < minBP {
+			return left, nil
+		}
+		if p.table.nonassoc[t.Value] && last != "" && p.table.nonassoc[last] && p.table.infix[last] == b {
+			return nil, p.Errorf("non-associative operator '%s' used in sequence", last)
+		}
+		p.Next()
+		right, err := p.expression(b.Right)
+		if err != nil {
+			return nil, err
+		}
+		left = &Expr{Kind: Binary, Token: t, Operands: []*Expr{left, right}}
+		last = t.Value
+	}
+}
+
+// prefix 前缀部分 变量 常量 括号 前缀运算符和函数调用
+func (p *Parser) prefix() (*Expr, error) {
+	t := p.Peek()
+	if t == nil {
+		return nil, p.Errorf("expected expression")
+	}
+	switch {
+	case t.IsValue():
+		p.Next()
+		if t.IsVariable() && p.Is("(") {
+			return p.call(t)
+		}
+		return &Expr{Kind: Primary, Token: t}, nil
+	case t.IsBracket() && t.Value == "(":
+		p.Next()
+		e, err := p.expression(0)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = p.Expect(")"); err != nil {
+			return nil, err
+		}
+		return e, nil
+	case t.IsOperator():
+		bp, ok := p.table.prefix[t.Value]
+		if !ok {
+			break
+		}
+		p.Next()
+		operand, err := p.expression(bp)
+		if err != nil {
+			return nil, err
+		}
+		return &Expr{Kind: Unary, Token: t, Operands: []*Expr{operand}}, nil
+	}
+	return nil, p.Errorf("expected expression")
+}
+
+// call 函数调用 f(a, b)
+func (p *Parser) call(name *lexer.Token) (*Expr, error) {
+	p.Next()
+	e := &Expr{Kind: Call, Token: name}
+	if p.Is(")") {
+		p.Next()
+		return e, nil
+	}
+	for {
+		arg, err := p.expression(0)
+		if err != nil {
+			return nil, err
+		}
+		e.Operands = append(e.Operands, arg)
+		if p.Is(",") {
+			p.Next()
+			continue
+		}
+		if _, err = p.Expect(")"); err != nil {
+			return nil, err
+		}
+		return e, nil
+	}
+}
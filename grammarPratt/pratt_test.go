@@ -0,0 +1,186 @@
+package grammarPratt
+
+import (
+	"github.com/esonhugh/compiler/grammarLL1/rule"
+	"github.com/esonhugh/compiler/lexer"
+	"testing"
+)
+
+func TestPrecedence(t *testing.T) {
+	cases := map[string]string{
+		"a + b * c":         "(+ a (* b c))",
+		"a * b + c":         "(+ (* a b) c)",
+		"a - b - c":         "(- (- a b) c)",
+		"(a + b) * c":       "(* (+ a b) c)",
+		"a || b && c == d":  "(|| a (&& b (== c d)))",
+		"a < b + 1":         "(< a (+ b 1))",
+		"- a * b":           "(* (- a) b)",
+		"! a && b":          "(&& (! a) b)",
+		"- - a":             "(- (- a))",
+		"a ++ * b":          "(* (++ post a) b)",
+		"- a ++":            "(- (++ post a))",
+		"a % b / c * d":     "(* (/ (% a b) c) d)",
+		"1 + 2 * (3 - 4)":   "(+ 1 (* 2 (- 3 4)))",
+		"a + b * c - d / e": "(- (+ a (* b c)) (/ d e))",
+	}
+	for code, want := range cases {
+		e, err := Parse(lexer.Analyse(code), SysY())
+		if err != nil {
+			t.Errorf("%s: %v", code, err)
+			continue
+		}
+		if e.String() != want {
+			t.Errorf("%s: got %s, want %s", code, e, want)
+		}
+	}
+}
+
+func TestAssociativity(t *testing.T) {
+	table := NewTable().
+		Infix(1, rule.Right, "=").
+		Infix(2, rule.NonAssoc, "<").
+		Infix(3, rule.Left, "+").
+		Infix(4, rule.Right, "^")
+	cases := map[string]string{
+		"a = b = c":   "(= a (= b c))",
+		"a ^ b ^ c":   "(^ a (^ b c))",
+		"a + b + c":   "(+ (+ a b) c)",
+		"a = b < c":   "(= a (< b c))",
+		"a + b ^ c":   "(+ a (^ b c))",
+		"a < b + c":   "(< a (+ b c))",
+		"(a < b) < c": "(< (< a b) c)",
+	}
+	for code, want := range cases {
+		e, err := Parse(lexer.Analyse(code), table)
+		if err != nil {
+			t.Errorf("%s: %v", code, err)
+			continue
+		}
+		if e.String() != want {
+			t.Errorf("%s: got %s, want %s", code, e, want)
+		}
+	}
+	if _, err := Parse(lexer.Analyse("a < b < c"), table); err == nil {
+		t.Error("a < b < c should be rejected for a non-associative operator")
+	}
+}
+
+func TestErrors(t *testing.T) {
+	cases := map[string]string{
+		"a +":     "line 1 col 4: expected expression, found end of input",
+		"( a + b": "line 1 col 8: expected ')', found end of input",
+		"a b":     "line 1 col 3: expected operator, found 'b'",
+		"* a":     "line 1 col 1: expected expression, found '*'",
+		"":        "line 1 col 1: expected expression, found end of input",
+	}
+	for code, want := range cases {
+		if e, err := Parse(lexer.Analyse(code), SysY()); err == nil || err.Error() != want {
+			t.Errorf("%q: got %v %v, want %s", code, e, err, want)
+		}
+	}
+}
+
+func TestCall(t *testing.T) {
+	// 词法分析器会把逗号和后面的字符连在一起 这里直接构造词法单元
+	tokens := []*lexer.Token{
+		lexer.NewToken(lexer.VARIABLE, "f"),
+		lexer.NewToken(lexer.BRACKET, "("),
+		lexer.NewToken(lexer.VARIABLE, "a"),
+		lexer.NewToken(lexer.OPERATOR, "+"),
+		lexer.NewToken(lexer.INTEGER, "1"),
+		lexer.NewToken(lexer.OPERATOR, ","),
+		lexer.NewToken(lexer.VARIABLE, "g"),
+		lexer.NewToken(lexer.BRACKET, "("),
+		lexer.NewToken(lexer.BRACKET, ")"),
+		lexer.NewToken(lexer.BRACKET, ")"),
+		lexer.NewToken(lexer.OPERATOR, "*"),
+		lexer.NewToken(lexer.VARIABLE, "b"),
+	}
+	e, err := Parse(tokens, SysY())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "(* (f call (+ a 1) (g call)) b)"; e.String() != want {
+		t.Errorf("got %s, want %s", e, want)
+	}
+	if want := "*(f(+(a 1) g) b)"; e.Tree().String() != want {
+		t.Errorf("tree: got %s, want %s", e.Tree(), want)
+	}
+}
+
+// statements 一个很小的递归下降语句分析器 表达式部分交给 Pratt 分析器
+//
+//	S -> x = E ; | if ( E ) S
+func statements(c *Cursor, table *Table) ([]string, error) {
+	var res []string
+	for c.Peek() != nil {
+		s, err := statement(c, table)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, s)
+	}
+	return res, nil
+}
+
+func statement(c *Cursor, table *Table) (string, error) {
+	switch {
+	case c.Is("if"):
+		c.Next()
+		if _, err := c.Expect("("); err != nil {
+			return "", err
+		}
+		cond, err := NewParser(c, table).Expression()
+		if err != nil {
+			return "", err
+		}
+		if _, err = c.Expect(")"); err != nil {
+			return "", err
+		}
+		body, err := statement(c, table)
+		if err != nil {
+			return "", err
+		}
+		return "if " + cond.String() + " " + body, nil
+	}
+	name := c.Next()
+	if name == nil || !name.IsVariable() {
+		return "", c.Errorf("expected statement")
+	}
+	if _, err := c.Expect("="); err != nil {
+		return "", err
+	}
+	value, err := NewParser(c, table).Expression()
+	if err != nil {
+		return "", err
+	}
+	if _, err = c.Expect(";"); err != nil {
+		return "", err
+	}
+	return name.Value + " = " + value.String() + ";", nil
+}
+
+func TestEmbedded(t *testing.T) {
+	code := "x = a + b * 2;\nif (x > 1 && ! y) if (z) y = - x;\nz = (y);"
+	c := NewCursor(lexer.Analyse(code))
+	res, err := statements(c, SysY())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"x = (+ a (* b 2));",
+		"if (&& (> x 1) (! y)) if z y = (- x);",
+		"z = y;",
+	}
+	if len(res) != len(want) {
+		t.Fatalf("got %v, want %v", res, want)
+	}
+	for i := range want {
+		if res[i] != want[i] {
+			t.Errorf("got %s, want %s", res[i], want[i])
+		}
+	}
+	if _, err := statements(NewCursor(lexer.Analyse("x = a + ;")), SysY()); err == nil {
+		t.Error("expected an error for a missing operand")
+	}
+}
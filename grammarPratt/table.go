@@ -0,0 +1,82 @@
+/*
+Package grammarPratt Pratt 算符优先表达式分析器包
+
+表达式的优先级不再用 E T F 这样的非终结符分层表示 而是由运算符表给出每个运算符的绑定强度
+分析器直接处理 []*lexer.Token 可以单独使用 也可以嵌入到递归下降的语句分析器中
+*/
+package grammarPratt
+
+import "github.com/esonhugh/compiler/grammarLL1/rule"
+
+// Binding 运算符的绑定强度 左绑定强度 Left 右绑定强度 Right
+type Binding struct {
+	Left  int
+	Right int
+}
+
+// Table 运算符表 前缀 中缀 后缀运算符分别登记
+type Table struct {
+	prefix  map[string]int
+	infix   map[string]Binding
+	postfix map[string]int
+	// nonassoc 不结合的中缀运算符
+	nonassoc map[string]bool
+}
+
+// NewTable 创建一个空的运算符表
+func NewTable() *Table {
+	return &Table{
+		prefix:   make(map[string]int),
+		infix:    make(map[string]Binding),
+		postfix:  make(map[string]int),
+		nonassoc: make(map[string]bool),
+	}
+}
+
+// Infix 登记中缀运算符 level 越大优先级越高 assoc 为 rule.Left rule.Right 或 rule.NonAssoc
+// 左结合时右绑定强度更高 右结合时左绑定强度更高 不结合时按左结合登记 但同级连用会报错
+func (t *Table) Infix(level int, assoc string, ops ...string) *Table {
+	for _, op := range ops {
+		switch assoc {
+		case rule.Right:
+			t.infix[op] = Binding{Left: 2*level + 1, Right: 2 * level}
+		case rule.NonAssoc:
+			t.infix[op] = Binding{Left: 2 * level, Right: 2*level + 1}
+			t.nonassoc[op] = true
+		default:
+			t.infix[op] = Binding{Left: 2 * level, Right: 2*level + 1}
+		}
+	}
+	return t
+}
+
+// Prefix 登记前缀运算符
+func (t *Table) Prefix(level int, ops ...string) *Table {
+	for _, op := range ops {
+		t.prefix[op] = 2 * level
+	}
+	return t
+}
+
+// Postfix 登记后缀运算符
+func (t *Table) Postfix(level int, ops ...string) *Table {
+	for _, op := range ops {
+		t.postfix[op] = 2 * level
+	}
+	return t
+}
+
+// SysY SysY/C 表达式的运算符表 从低到高
+//
+//	||  &&  == !=  < > <= >=  + -  * / %  前缀 ! - +  后缀 ++ --
+func SysY() *Table {
+	return NewTable().
+		Infix(1, rule.Left, "||").
+		Infix(2, rule.Left, "&&").
+		Infix(3, rule.Left, "==", "!=").
+		Infix(4, rule.Left, "<", ">", "<=", ">=").
+		Infix(5, rule.Left, "+", "-").
+		Infix(6, rule.Left, "*", "/", "%").
+		Prefix(7, "!", "-", "+").
+		Postfix(8, "++", "--")
+}
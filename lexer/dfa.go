@@ -0,0 +1,74 @@
+package lexer
+
+import "sort"
+
+// DFA 确定有限自动机 状态用已经读入的字符串表示 开始状态为空串
+// Edges[s][c] 为状态 s 读入字符 c 后到达的状态 Accept 为可以结束的状态及其词法单元类型
+type DFA struct {
+	Name   string
+	Edges  map[string]map[string]string
+	Accept map[string]TokenType
+}
+
+// States 全部状态 开始状态在前 其余按长度和字典序排列
+func (d *DFA) States() []string {
+	seen := map[string]bool{"": true}
+	for from, edges := range d.Edges {
+		seen[from] = true
+		for _, to := range edges {
+			seen[to] = true
+		}
+	}
+	for s := range d.Accept {
+		seen[s] = true
+	}
+	var res []string
+	for s := range seen {
+		res = append(res, s)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if len(res[i]) != len(res[j]) {
+			return len(res[i]) < len(res[j])
+		}
+		return res[i] < res[j]
+	})
+	return res
+}
+
+// Inputs 状态 s 可以读入的字符 按字典序排列
+func (d *DFA) Inputs(s string) []string {
+	var res []string
+	for c := range d.Edges[s] {
+		res = append(res, c)
+	}
+	sort.Strings(res)
+	return res
+}
+
+// newOperatorDFA 按 MakeOp 中的状态转移构造运算符的自动机 每个运算符最多两个字符 状态就是已经读入的运算符
+func newOperatorDFA() *DFA {
+	d := &DFA{Name: "operator", Edges: make(map[string]map[string]string), Accept: make(map[string]TokenType)}
+	second := map[string]string{
+		"+": "+=", "-": "-=", "*": "=", "/": "=", ">": "=>", "<": "=<>",
+		"=": "=", "!": "=", "&": "&=", "|": "|=", "^": "^=", "%": "=", ":": "=",
+		",": "", ";": "",
+	}
+	d.Edges[""] = make(map[string]string)
+	for first, next := range second {
+		d.Edges[""][first] = first
+		if first != ":" {
+			d.Accept[first] = OPERATOR
+		}
+		for _, c := range next {
+			if d.Edges[first] == nil {
+				d.Edges[first] = make(map[string]string)
+			}
+			d.Edges[first][string(c)] = first + string(c)
+			d.Accept[first+string(c)] = OPERATOR
+		}
+	}
+	return d
+}
+
+// OperatorDFA 与 MakeOp 识别相同运算符的自动机 只用于导出 不参与词法分析
+var OperatorDFA = newOperatorDFA()
@@ -0,0 +1,43 @@
+package lexer
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestOperatorDFA(t *testing.T) {
+	cases := map[string][]string{
+		"a+=b":   {"a", "+=", "b"},
+		"a<>b":   {"a", "<>", "b"},
+		"a<<b;":  {"a", "<<", "b", ";"},
+		"a&&!b":  {"a", "&&", "!", "b"},
+		"a:=b%c": {"a", ":=", "b", "%", "c"},
+		"a++ -b": {"a", "++", "-", "b"},
+		"a>=b>c": {"a", ">=", "b", ">", "c"},
+	}
+	for code, want := range cases {
+		tokens := Analyse(code)
+		if len(tokens) != len(want) {
+			t.Errorf("%s: got %d tokens", code, len(tokens))
+			continue
+		}
+		for i, tok := range tokens {
+			if tok.Value != want[i] {
+				t.Errorf("%s: token %d is %q, want %q", code, i, tok.Value, want[i])
+			}
+		}
+	}
+	// 自动机的每个接受状态 MakeOp 都要读成一个运算符
+	for state := range OperatorDFA.Accept {
+		if tok := NewLexer(bytes.NewBufferString(state+" "), EndToken).MakeOp(); tok.Value != state {
+			t.Errorf("%s: MakeOp reads %q", state, tok.Value)
+		}
+	}
+	states := OperatorDFA.States()
+	if states[0] != "" || len(OperatorDFA.Inputs("<")) != 3 {
+		t.Fatalf("unexpected states %q", states)
+	}
+	if _, ok := OperatorDFA.Accept[":"]; ok {
+		t.Fatal(": alone is not an operator")
+	}
+}
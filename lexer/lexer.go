@@ -50,11 +50,16 @@ func NewLexer(r io.Reader, et string) *Lexer {
 // Analyse 分析 Token 列表
 func (l *Lexer) Analyse() []*Token {
 	tokens := make([]*Token, 0)
+	// 上一轮新产生的词法单元从 mark 开始 位置为上一轮第一个字符的位置
+	mark, row, column := 0, 0, 0
 	for l.HasNext() {
+		locate(tokens[mark:], row, column)
+		mark = len(tokens)
 		c := l.Next()
 		if c == EndToken {
 			break
 		}
+		row, column = l.GetLine(), l.GetColumn()-1
 		lookahead := l.Peek()
 
 		if c == " " || c == "\n" || c == "\t" {
@@ -119,10 +124,20 @@ func (l *Lexer) Analyse() []*Token {
 		tokens = append(tokens, l.MakeErr())
 
 	}
+	locate(tokens[mark:], row, column)
 
 	return tokens
 }
 
+// locate 给还没有位置信息的词法单元补上位置
+func locate(tokens []*Token, row int, column int) {
+	for _, t := range tokens {
+		if t.Row == 0 {
+			t.Row, t.Column = row, column
+		}
+	}
+}
+
 // MakeComment 分析注释 // 类
 func (l *Lexer) MakeComment() *Token {
 	s := ""
@@ -0,0 +1,273 @@
+package parser
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/esonhugh/compiler/grammar"
+	"github.com/esonhugh/compiler/grammarEarley"
+	"github.com/esonhugh/compiler/grammarLL1"
+	"github.com/esonhugh/compiler/grammarLL1/analysisTable"
+	firstset "github.com/esonhugh/compiler/grammarLL1/first"
+	"github.com/esonhugh/compiler/grammarLL1/follow"
+	"github.com/esonhugh/compiler/grammarLL1/hygiene"
+	"github.com/esonhugh/compiler/grammarLL1/rule"
+	"github.com/esonhugh/compiler/grammarLR"
+	"github.com/esonhugh/compiler/grammarLR/actionTable"
+	"github.com/esonhugh/compiler/grammarOP"
+	"github.com/esonhugh/compiler/tree"
+	"strings"
+)
+
+// 内置的后端
+const (
+	RecursiveDescent = "rd"
+	LL1              = "ll1"
+	SLR1             = "slr1"
+	LR1              = "lr1"
+	LALR1            = "lalr1"
+	GLR              = "glr"
+	Earley           = "earley"
+	OperatorPrec     = "op"
+)
+
+func init() {
+	Register(RecursiveDescent, newRecursiveDescent)
+	Register(LL1, newLL1)
+	Register(SLR1, lrFactory(grammarLR.SLR1))
+	Register(LR1, lrFactory(grammarLR.LR1))
+	Register(LALR1, lrFactory(grammarLR.LALR1))
+	Register(GLR, newGLR)
+	Register(Earley, newEarley)
+	Register(OperatorPrec, newOperatorPrecedence)
+}
+
+// cancelled 开始分析之前检查 ctx
+func cancelled(ctx context.Context) []Diagnostic {
+	if err := ctx.Err(); err != nil {
+		return []Diagnostic{{Message: err.Error()}}
+	}
+	return nil
+}
+
+// load 读入规则并检查文法 开始符号未定义或者有未定义的非终结符时返回错误
+func load(rules string, start string) (*rule.Rule, error) {
+	r, err := rule.Parse(rules)
+	if err != nil {
+		return nil, err
+	}
+	if report := hygiene.Check(r, start); !report.IsUsable() {
+		return nil, errors.New("parser: grammar is not usable:\n" + report.String())
+	}
+	return r, nil
+}
+
+// recursiveDescent 递归下降分析器 只能分析 grammar.Rules 描述的表达式
+// 与其他后端一样只分析开始符号 E 推导出的句子 ; 分隔的语句序列由 grammar.Analyse 分析
+type recursiveDescent struct{}
+
+func newRecursiveDescent(rules string, start string) (Parser, error) {
+	if rules == "" {
+		return recursiveDescent{}, nil
+	}
+	r, err := rule.Parse(rules)
+	if err != nil {
+		return nil, err
+	}
+	builtin := rule.MustParse(grammar.Rules)
+	if r.String() != builtin.String() || (start != "" && start != "E") {
+		return nil, errors.New("parser: the recursive descent backend only supports grammar.Rules")
+	}
+	return recursiveDescent{}, nil
+}
+
+func (recursiveDescent) Parse(ctx context.Context, src TokenSource) (*tree.Node, []Diagnostic) {
+	if d := cancelled(ctx); d != nil {
+		return nil, d
+	}
+	tokens := Tokens(src)
+	prod, d := grammar.AnalyseSentence(tokens)
+	if d != nil {
+		return nil, []Diagnostic{at(tokens, d.Found, fmt.Sprintf("expected %s, found %s", strings.Join(d.Expected, " or "), found(d.Found)))}
+	}
+	var steps []tree.Step
+	for _, p := range prod {
+		steps = append(steps, tree.Step{Derive: p.Type != "kill", Left: p.Origin, Right: tree.Symbols(p.Next)})
+	}
+	root, err := tree.NewBuilder(steps, tokens).Build("E")
+	if err != nil {
+		return nil, []Diagnostic{{Message: err.Error()}}
+	}
+	return root, nil
+}
+
+// ll1 LL(1) 分析器 出错时用短语级恢复找出全部错误
+// 分析表 同步用的 FOLLOW 集和 Matcher 在创建时构造一次 每次分析只新建分析栈
+type ll1 struct {
+	start   string
+	table   analysisTable.SymbolTable
+	follow  follow.FollowSet
+	matcher *rule.Matcher
+}
+
+func newLL1(rules string, start string) (Parser, error) {
+	r, table, conflicts, err := grammarLL1.BuildTable(rules, start)
+	if err != nil {
+		return nil, err
+	}
+	if !conflicts.IsLL1() {
+		return nil, errors.New("parser: grammar is not LL(1):\n" + conflicts.String())
+	}
+	return &ll1{
+		start:   start,
+		table:   table,
+		follow:  follow.GetFollowSet(r, start, firstset.GetFirstSet(r)),
+		matcher: r.Matcher(),
+	}, nil
+}
+
+func (p *ll1) Parse(ctx context.Context, src TokenSource) (*tree.Node, []Diagnostic) {
+	if d := cancelled(ctx); d != nil {
+		return nil, d
+	}
+	tokens := Tokens(src)
+	g := grammarLL1.NewGrammar(tokens, bytes.NewBufferString(p.start), grammarLL1.EndToken, p.table)
+	g.Matcher = p.matcher
+	prod, diagnostics := g.Recover(p.follow, grammarLL1.PhraseLevel)
+	if len(diagnostics) != 0 {
+		var res []Diagnostic
+		for _, d := range diagnostics {
+			res = append(res, Diagnostic{Row: d.Row, Column: d.Column, Message: fmt.Sprintf("expected %s, found %s", strings.Join(d.Expected, " or "), found(d.Found))})
+		}
+		return nil, res
+	}
+	var steps []tree.Step
+	for _, pr := range prod {
+		if pr.Type == "kill" && pr.Target == grammarLL1.EndToken {
+			continue
+		}
+		steps = append(steps, tree.Step{Derive: pr.Type != "kill", Left: pr.Origin, Right: tree.Symbols(pr.Next)})
+	}
+	root, err := tree.NewBuilder(steps, tokens).Build(p.start)
+	if err != nil {
+		return nil, []Diagnostic{{Message: err.Error()}}
+	}
+	return root, nil
+}
+
+// lr LR 分析器 分析表有冲突时不能创建
+type lr struct {
+	table *actionTable.Table
+}
+
+func lrFactory(method string) Factory {
+	return func(rules string, start string) (Parser, error) {
+		table, _, err := grammarLR.BuildTable(rules, start, method)
+		if err != nil {
+			return nil, err
+		}
+		if !table.IsConflictFree() {
+			return nil, errors.New("parser: grammar is not " + method + ":\n" + table.ConflictString())
+		}
+		return &lr{table: table}, nil
+	}
+}
+
+func (p *lr) Parse(ctx context.Context, src TokenSource) (*tree.Node, []Diagnostic) {
+	if d := cancelled(ctx); d != nil {
+		return nil, d
+	}
+	tokens := Tokens(src)
+	g := grammarLR.NewGrammar(tokens, p.table)
+	if _, ok := g.Analyze(); !ok {
+		t, expected := g.Failure()
+		return nil, []Diagnostic{at(tokens, t, fmt.Sprintf("expected %s, found %s", strings.Join(expected, " or "), found(t)))}
+	}
+	return g.Tree, nil
+}
+
+// glr GLR 分析器 文法有冲突也可以分析 有歧义时返回第一棵语法树
+type glr struct {
+	table *actionTable.Table
+}
+
+func newGLR(rules string, start string) (Parser, error) {
+	table, _, err := grammarLR.BuildTable(rules, start, grammarLR.LALR1)
+	if err != nil {
+		return nil, err
+	}
+	return &glr{table: table}, nil
+}
+
+func (p *glr) Parse(ctx context.Context, src TokenSource) (*tree.Node, []Diagnostic) {
+	if d := cancelled(ctx); d != nil {
+		return nil, d
+	}
+	forest, err := grammarLR.NewGLR(Tokens(src), p.table).Parse()
+	return first(forest, err)
+}
+
+// earley Earley 分析器 可以分析任意上下文无关文法 有歧义时返回第一棵语法树
+type earley struct {
+	parser *grammarEarley.Parser
+}
+
+func newEarley(rules string, start string) (Parser, error) {
+	r, err := load(rules, start)
+	if err != nil {
+		return nil, err
+	}
+	return &earley{parser: grammarEarley.NewParser(r, start)}, nil
+}
+
+func (p *earley) Parse(ctx context.Context, src TokenSource) (*tree.Node, []Diagnostic) {
+	if d := cancelled(ctx); d != nil {
+		return nil, d
+	}
+	forest, err := p.parser.Parse(Tokens(src))
+	return first(forest, err)
+}
+
+// first 语法森林中的第一棵语法树
+func first(forest *tree.Forest, err error) (*tree.Node, []Diagnostic) {
+	if err != nil {
+		return nil, []Diagnostic{{Message: err.Error()}}
+	}
+	trees := forest.Trees(1)
+	if len(trees) == 0 {
+		return nil, []Diagnostic{{Message: "no parse tree"}}
+	}
+	return trees[0], nil
+}
+
+// operatorPrecedence 算符优先分析器 语法树中没有单非终结符的归约
+type operatorPrecedence struct {
+	table *grammarOP.Table
+}
+
+func newOperatorPrecedence(rules string, start string) (Parser, error) {
+	r, err := load(rules, start)
+	if err != nil {
+		return nil, err
+	}
+	table, err := grammarOP.GetTable(r, start)
+	if err != nil {
+		return nil, err
+	}
+	if !table.IsOperatorPrecedence() {
+		return nil, errors.New("parser: grammar is not an operator precedence grammar:\n" + table.ConflictString())
+	}
+	return &operatorPrecedence{table: table}, nil
+}
+
+func (p *operatorPrecedence) Parse(ctx context.Context, src TokenSource) (*tree.Node, []Diagnostic) {
+	if d := cancelled(ctx); d != nil {
+		return nil, d
+	}
+	g := grammarOP.NewGrammar(Tokens(src), p.table)
+	if _, err := g.Analyze(); err != nil {
+		return nil, []Diagnostic{{Message: err.Error()}}
+	}
+	return g.Tree, nil
+}
@@ -0,0 +1,144 @@
+/*
+Package parser 各个语法分析器的统一接口
+
+每个分析器作为一个后端按名字注册 工具 命令行和对照测试都通过名字选择后端
+后端不向终端输出任何内容 分析结果为语法树 错误以 Diagnostic 返回
+*/
+package parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/esonhugh/compiler/grammarLL1/rule"
+	"github.com/esonhugh/compiler/lexer"
+	"github.com/esonhugh/compiler/tree"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Parser 语法分析器 分析成功时返回语法树 Diagnostic 为空
+type Parser interface {
+	Parse(ctx context.Context, src TokenSource) (*tree.Node, []Diagnostic)
+}
+
+// Factory 根据规则和开始符号创建一个后端
+type Factory func(rules string, start string) (Parser, error)
+
+// TokenSource 词法单元的来源 没有更多的词法单元时 Next 返回 nil
+type TokenSource interface {
+	Next() *lexer.Token
+}
+
+// Diagnostic 一个语法错误 没有位置信息时 Row 和 Column 为 0
+type Diagnostic struct {
+	Row     int
+	Column  int
+	Message string
+}
+
+// String 输出错误 line 3 col 7: expected ')', found 'i'
+func (d Diagnostic) String() string {
+	if d.Row == 0 {
+		return d.Message
+	}
+	return fmt.Sprintf("line %d col %d: %s", d.Row, d.Column, d.Message)
+}
+
+var (
+	mu        sync.RWMutex
+	factories = make(map[string]Factory)
+)
+
+// Register 注册一个后端 名字重复时 panic
+func Register(name string, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	if _, ok := factories[name]; ok {
+		panic("parser: backend " + name + " registered twice")
+	}
+	factories[name] = factory
+}
+
+// New 按名字创建后端 多个字符的开始符号写作 <Expr> 与规则中相同
+func New(name string, rules string, start string) (Parser, error) {
+	mu.RLock()
+	factory, ok := factories[name]
+	mu.RUnlock()
+	if !ok {
+		return nil, errors.New("parser: unknown backend " + name + ", available: " + strings.Join(Backends(), " "))
+	}
+	// 规则读入失败时由后端报告错误
+	if r, err := rule.Parse(rules); err == nil {
+		start = r.Symbol(start)
+	}
+	return factory(rules, start)
+}
+
+// Backends 全部已注册的后端的名字
+func Backends() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	var names []string
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// sliceSource 由词法单元切片得到的 TokenSource
+type sliceSource struct {
+	tokens []*lexer.Token
+	pos    int
+}
+
+func (s *sliceSource) Next() *lexer.Token {
+	if s.pos >= len(s.tokens) {
+		return nil
+	}
+	s.pos++
+	return s.tokens[s.pos-1]
+}
+
+// FromTokens 由词法单元切片得到 TokenSource
+func FromTokens(tokens []*lexer.Token) TokenSource {
+	return &sliceSource{tokens: tokens}
+}
+
+// FromString 对源代码做词法分析得到 TokenSource
+func FromString(code string) TokenSource {
+	return FromTokens(lexer.Analyse(code))
+}
+
+// Tokens 读出全部词法单元 跳过注释 遇到结束符为止
+func Tokens(src TokenSource) []*lexer.Token {
+	var res []*lexer.Token
+	for t := src.Next(); t != nil && t.Typ != lexer.END; t = src.Next() {
+		if t.Typ != lexer.COMMENT {
+			res = append(res, t)
+		}
+	}
+	return res
+}
+
+// at 在词法单元的位置上的错误 结束符没有位置时使用最后一个词法单元之后的位置
+func at(tokens []*lexer.Token, t *lexer.Token, message string) Diagnostic {
+	if t != nil && t.Typ == lexer.END && len(tokens) > 0 {
+		last := tokens[len(tokens)-1]
+		return Diagnostic{Row: last.Row, Column: last.Column + len(last.Value), Message: message}
+	}
+	if t == nil {
+		return Diagnostic{Message: message}
+	}
+	return Diagnostic{Row: t.Row, Column: t.Column, Message: message}
+}
+
+// found 错误信息中的当前词法单元
+func found(t *lexer.Token) string {
+	if t == nil || t.Typ == lexer.END {
+		return "end of input"
+	}
+	return "'" + t.Value + "'"
+}
@@ -0,0 +1,152 @@
+package parser
+
+import (
+	"context"
+	"github.com/esonhugh/compiler/grammar"
+	"github.com/esonhugh/compiler/grammarLL1/rule"
+	"strings"
+	"testing"
+)
+
+const expression = "E->E+T|T\nT->T*F|F\nF->(E)|i"
+
+// differential 用多个后端分析同样的输入 语法树必须相同
+func differential(t *testing.T, rules string, start string, backends []string, inputs []string) {
+	var parsers []Parser
+	for _, name := range backends {
+		p, err := New(name, rules, start)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		parsers = append(parsers, p)
+	}
+	for _, code := range inputs {
+		var want string
+		for i, p := range parsers {
+			root, diagnostics := p.Parse(context.Background(), FromString(code))
+			got := ""
+			if len(diagnostics) == 0 {
+				got = root.String()
+			}
+			if i == 0 {
+				want = got
+				continue
+			}
+			if got != want {
+				t.Errorf("%s: %s got %q, %s got %q", code, backends[0], want, backends[i], got)
+			}
+		}
+	}
+}
+
+func TestRecursiveDescentAndLL1(t *testing.T) {
+	differential(t, grammar.Rules, "E", []string{RecursiveDescent, LL1},
+		[]string{"a", "a+b*c", "(a-b)/c", "a*(b+(c-d))", "a+", "(a", "a b", "+a"})
+}
+
+func TestLRBackends(t *testing.T) {
+	differential(t, expression, "E", []string{SLR1, LR1, LALR1, GLR, Earley},
+		[]string{"a", "a+b*c", "(a+b)*c", "a*(b+(c*d))", "a+", "(a", "a b", "*a"})
+}
+
+func TestDiagnostics(t *testing.T) {
+	cases := map[string]string{
+		RecursiveDescent: "line 1 col 7: expected ')' or operator, found end of input",
+		LL1:              "line 1 col 7: expected ), found end of input",
+	}
+	for name, want := range cases {
+		p, err := New(name, grammar.Rules, "E")
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, diagnostics := p.Parse(context.Background(), FromString("(a + b"))
+		if len(diagnostics) != 1 || diagnostics[0].String() != want {
+			t.Errorf("%s: got %v, want %s", name, diagnostics, want)
+		}
+	}
+	p, _ := New(SLR1, expression, "E")
+	_, diagnostics := p.Parse(context.Background(), FromString("a + * b"))
+	if want := "line 1 col 5: expected ( or i, found '*'"; len(diagnostics) != 1 || diagnostics[0].String() != want {
+		t.Errorf("slr1: got %v, want %s", diagnostics, want)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	if got := strings.Join(Backends(), " "); got != "earley glr lalr1 ll1 lr1 op rd slr1" {
+		t.Fatalf("unexpected backends %s", got)
+	}
+	if _, err := New("cyk", expression, "E"); err == nil {
+		t.Fatal("unknown backend should be rejected")
+	}
+	if _, err := New(LL1, expression, "E"); err == nil {
+		t.Fatal("left recursive grammar is not LL(1)")
+	}
+	if _, err := New(RecursiveDescent, expression, "E"); err == nil {
+		t.Fatal("recursive descent only supports its built-in grammar")
+	}
+	p, err := New(OperatorPrec, expression, "E")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root, diagnostics := p.Parse(context.Background(), FromString("a+b*c")); len(diagnostics) != 0 || root.String() != "E(F(i) + T(F(i) * F(i)))" {
+		t.Fatalf("unexpected result %v %v", root, diagnostics)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, diagnostics := p.Parse(ctx, FromString("a")); len(diagnostics) != 1 {
+		t.Fatal("a cancelled context should stop parsing")
+	}
+}
+
+func TestUnusableGrammar(t *testing.T) {
+	for _, name := range Backends() {
+		if _, err := New(name, grammar.Rules, "X"); err == nil {
+			t.Errorf("%s: undefined start symbol should be rejected", name)
+		}
+		if _, err := New(name, "E->E+T|T\nT->i|X", "E"); err == nil {
+			t.Errorf("%s: undefined nonterminal should be rejected", name)
+		}
+	}
+}
+
+func TestSentence(t *testing.T) {
+	// 与其他后端一样 递归下降后端只接受一条表达式
+	p, _ := New(RecursiveDescent, "", "")
+	want := "line 1 col 2: expected operator, found ';'"
+	if _, diagnostics := p.Parse(context.Background(), FromString("a;\nb+c")); len(diagnostics) != 1 || diagnostics[0].String() != want {
+		t.Fatalf("got %v, want %s", diagnostics, want)
+	}
+	want = "line 1 col 1: expected '(' or identifier, found end of input"
+	if _, diagnostics := p.Parse(context.Background(), FromString("")); len(diagnostics) != 1 || diagnostics[0].String() != want {
+		t.Fatalf("got %v, want %s", diagnostics, want)
+	}
+}
+
+func TestMultiCharacterNonterminals(t *testing.T) {
+	rules := "<Expr>-><Expr>+<Term>|<Term>\n<Term>->(<Expr>)|i"
+	names := rule.MustParse(rules).Names
+	for _, name := range []string{SLR1, LR1, LALR1, GLR, Earley} {
+		p, err := New(name, rules, "<Expr>")
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		root, diagnostics := p.Parse(context.Background(), FromString("a+(b)"))
+		if len(diagnostics) != 0 {
+			t.Fatalf("%s: %v", name, diagnostics)
+		}
+		if got := root.Format(names); got != "Expr(Expr(Term(i)) + Term(( Expr(Term(i)) )))" {
+			t.Errorf("%s: got %s", name, got)
+		}
+		want := `Expr
+=> Expr + Term    (Expr -> Expr + Term)
+=> Expr + ( Expr )    (Term -> ( Expr ))
+=> Expr + ( Term )    (Expr -> Term)
+=> Expr + ( i )    (Term -> i)
+=> Term + ( i )    (Expr -> Term)
+=> i + ( i )    (Term -> i)
+`
+		if got := root.Rightmost(names).String(); got != want {
+			t.Errorf("%s: got\n%s", name, got)
+		}
+	}
+}
@@ -6,51 +6,108 @@ package print
 import (
 	"github.com/esonhugh/compiler/grammar"
 	"github.com/esonhugh/compiler/grammarLL1"
+	"github.com/esonhugh/compiler/grammarLL1/rule"
+	"github.com/esonhugh/compiler/grammarLR"
 	"github.com/esonhugh/compiler/lexer"
-	"github.com/esonhugh/compiler/util/transfer"
+	"github.com/esonhugh/compiler/tree"
 	"fmt"
 	"github.com/gookit/color"
-	"strings"
 )
 
 // PrintGrammar 和 Grammar 库一起使用 用于输出语法分析结果
+// gram 为 grammar.Analyse 得到的最左推导 先构造语法树再输出每条语句的推导过程 符号按 grammar.Rules 中的 %name 显示
 func PrintGrammar(gram []*grammar.Production) {
-	start := "E"
-	matched := ""
-	for i := 0; i < len(gram); i++ {
-		if gram[i].Type == "kill" {
-			fmt.Printf("规约：%v\n", transfer.Transfer(gram[i].Target))
-			start = strings.Replace(start, gram[i].Target, "", 1)
-			matched += gram[i].Target
-			color.Green.Printf("%s", transfer.Transfer(matched))
-			color.Red.Printf(transfer.Transfer(start + "\n"))
-			i++
-		} else {
-			fmt.Printf("推导：%v--->%v\n", transfer.Transfer(gram[i].Origin), transfer.Transfer(gram[i].Next))
-			start = strings.Replace(start, gram[i].Origin, gram[i].Next, 1)
-			color.Green.Printf("%s", transfer.Transfer(matched))
-			color.Red.Printf(transfer.Transfer(start + "\n"))
-		}
+	var steps []tree.Step
+	for _, p := range gram {
+		steps = append(steps, tree.Step{Derive: p.Type != "kill", Left: p.Origin, Right: tree.Symbols(p.Next)})
 	}
+	printLeftmost(steps, rule.MustParse(grammar.Rules).Names)
 }
 
 // PrintGrammarLL1 和 GrammarLL1 库一起使用 用于输出语法分析结果
-func PrintGrammarLL1(gram []*grammarLL1.Production) {
-	start := "E"
-	matched := ""
-	for i := 0; i < len(gram)-1; i++ {
-		if gram[i].Type == "kill" {
-			fmt.Printf("规约：%v\n", transfer.Transfer(gram[i].Target))
-			start = strings.Replace(start, gram[i].Target, "", 1)
-			matched += gram[i].Target
-			color.Green.Printf("%s", transfer.Transfer(matched))
-			color.Red.Printf(transfer.Transfer(start + "\n"))
+// 句型从第一次推导的左部开始 不再假定开始符号是 E names 为文法的 Names 可以为 nil
+func PrintGrammarLL1(gram []*grammarLL1.Production, names map[string]string) {
+	var steps []tree.Step
+	for _, p := range gram {
+		if p.Type == "kill" && p.Target == grammarLL1.EndToken {
+			continue
+		}
+		steps = append(steps, tree.Step{Derive: p.Type != "kill", Left: p.Origin, Right: tree.Symbols(p.Next)})
+	}
+	printLeftmost(steps, names)
+}
+
+// printLeftmost 由最左推导的步骤构造语法树 每棵树输出一次最左推导
+func printLeftmost(steps []tree.Step, names map[string]string) {
+	b := tree.NewBuilder(steps, nil)
+	for !b.Done() {
+		root, err := b.Build(steps[0].Left)
+		if err != nil {
+			color.Redln(err.Error())
+			return
+		}
+		PrintDerivation(root.Leftmost(names))
+	}
+}
+
+// PrintGrammarLR 和 GrammarLR 库一起使用 按顺序输出归约过程 即倒过来的最右推导 names 与 PrintGrammarLL1 相同
+func PrintGrammarLR(gram []*grammarLR.Production, names map[string]string) {
+	var reductions []tree.Step
+	for _, p := range gram {
+		reductions = append(reductions, tree.Step{Derive: true, Left: p.Origin, Right: tree.Symbols(p.Next)})
+	}
+	root, err := tree.FromReductions(reductions)
+	if err != nil {
+		color.Redln(err.Error())
+		return
+	}
+	PrintDerivation(root.Reductions(names))
+}
+
+// PrintDerivation 输出推导过程 已经确定的终结符为绿色 其余部分为红色
+// 最左推导确定的是左边的终结符 最右推导和归约确定的是右边的终结符
+func PrintDerivation(d *tree.Derivation) {
+	nonterminals := make(map[string]bool)
+	for _, s := range d.Steps {
+		nonterminals[s.Left] = true
+	}
+	show := func(form []string) {
+		if len(form) == 0 {
+			color.Green.Println("ε")
+			return
+		}
+		// 已经确定的部分在 [from, to) 之间
+		from, to := 0, 0
+		if d.Kind == tree.LeftmostDerivation {
+			for to < len(form) && !nonterminals[form[to]] {
+				to++
+			}
+		} else {
+			from, to = len(form), len(form)
+			for from > 0 && !nonterminals[form[from-1]] {
+				from--
+			}
+		}
+		for i, x := range form {
+			if i != 0 {
+				fmt.Print(" ")
+			}
+			if i >= from && i < to {
+				color.Green.Print(x)
+			} else {
+				color.Red.Print(x)
+			}
+		}
+		fmt.Println()
+	}
+	show(d.Forms[0])
+	for i := range d.Steps {
+		if d.Kind == tree.Reduction {
+			fmt.Printf("归约：%s\n", d.Production(i))
 		} else {
-			fmt.Printf("推导：%v--->%v\n", transfer.Transfer(gram[i].Origin), transfer.Transfer(gram[i].Next))
-			start = strings.Replace(start, gram[i].Origin, gram[i].Next, 1)
-			color.Green.Printf("%s", transfer.Transfer(matched))
-			color.Red.Printf(transfer.Transfer(start + "\n"))
+			fmt.Printf("推导：%s\n", d.Production(i))
 		}
+		show(d.Forms[i+1])
 	}
 }
 
@@ -0,0 +1,105 @@
+package tree
+
+import (
+	"fmt"
+	"github.com/esonhugh/compiler/grammarLL1/util"
+	"github.com/esonhugh/compiler/lexer"
+)
+
+// Step 最左推导中的一步 Derive 为用产生式 Left->Right 展开 否则为匹配一个终结符或者 ε
+// Right 中的每个元素是一个符号 可以有多个字符 首字母大写的是非终结符 ε 产生式的右部为 [&] 或者为空
+type Step struct {
+	Derive bool
+	Left   string
+	Right  []string
+}
+
+// Symbols 把规则中的右部拆成符号 rule.Rule 中的符号都是单个字符 & 保持为一个符号
+func Symbols(right string) []string {
+	res := make([]string, 0, len(right))
+	for i := 0; i < len(right); i++ {
+		res = append(res, right[i:i+1])
+	}
+	return res
+}
+
+// Builder 由自顶向下分析器输出的最左推导步骤构造语法树 匹配到的终结符依次对应 tokens
+type Builder struct {
+	steps  []Step
+	pos    int
+	tokens []*lexer.Token
+	next   int
+}
+
+// NewBuilder 创建一个构造器 tokens 可以为空 此时叶子结点没有词法单元
+func NewBuilder(steps []Step, tokens []*lexer.Token) *Builder {
+	return &Builder{steps: steps, tokens: tokens}
+}
+
+// Done 全部步骤都已经用完
+func (b *Builder) Done() bool {
+	return b.pos >= len(b.steps)
+}
+
+// Build 从 symbol 开始构造一棵语法树 步骤与推导不一致时返回错误
+func (b *Builder) Build(symbol string) (*Node, error) {
+	if symbol == "&" || util.IsTerminal(symbol[0]) {
+		b.pos++
+		if symbol == "&" {
+			return Leaf(symbol, nil), nil
+		}
+		var token *lexer.Token
+		if b.next < len(b.tokens) {
+			token = b.tokens[b.next]
+		}
+		b.next++
+		return Leaf(symbol, token), nil
+	}
+	if b.Done() {
+		return nil, fmt.Errorf("tree: derivation ends before %s is expanded", symbol)
+	}
+	s := b.steps[b.pos]
+	if !s.Derive || s.Left != symbol {
+		return nil, fmt.Errorf("tree: step %d does not expand %s", b.pos, symbol)
+	}
+	b.pos++
+	n := New(s.Left)
+	for _, symbol := range s.Right {
+		c, err := b.Build(symbol)
+		if err != nil {
+			return nil, err
+		}
+		n.Children = append(n.Children, c)
+	}
+	return n, nil
+}
+
+// FromReductions 由自底向上分析器的归约序列构造语法树
+// 归约 A->α 时 α 中的非终结符依次取最近归约得到 还没有被使用的结点 终结符为没有词法单元的叶子
+func FromReductions(reductions []Step) (*Node, error) {
+	var stack []*Node
+	for i, r := range reductions {
+		right := r.Right
+		if len(right) == 1 && right[0] == "&" {
+			right = nil
+		}
+		children := make([]*Node, len(right))
+		for j := len(right) - 1; j >= 0; j-- {
+			symbol := right[j]
+			if util.IsTerminal(symbol[0]) {
+				children[j] = Leaf(symbol, nil)
+				continue
+			}
+			if len(stack) == 0 || stack[len(stack)-1].Symbol != symbol {
+				return nil, fmt.Errorf("tree: reduction %d needs %s", i, symbol)
+			}
+			children[j] = stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+		}
+		stack = append(stack, Reduce(r.Left, children))
+	}
+	if len(stack) != 1 {
+		return nil, fmt.Errorf("tree: reductions leave %d trees", len(stack))
+	}
+	return stack[0], nil
+}
@@ -0,0 +1,184 @@
+package tree
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// 推导的种类
+const (
+	LeftmostDerivation  = "leftmost"  // 最左推导 自顶向下分析器的输出顺序
+	RightmostDerivation = "rightmost" // 最右推导
+	Reduction           = "reduction" // 最右推导的逆过程 自底向上分析器的归约顺序
+)
+
+// Rewrite 推导中的一步 把句型中第 Position 个符号 Left 替换为 Right
+// 归约时反过来 把从 Position 开始的句柄 Right 替换为 Left
+type Rewrite struct {
+	Left     string   `json:"left"`
+	Right    []string `json:"right"`
+	Position int      `json:"position"`
+}
+
+// Derivation 由语法树得到的推导 Forms 为依次得到的句型 每个句型是符号的列表
+// 第 i 步把 Forms[i] 变为 Forms[i+1] 符号按 Name 显示 可以有多个字符
+type Derivation struct {
+	Kind  string     `json:"kind"`
+	Forms [][]string `json:"forms"`
+	Steps []Rewrite  `json:"steps"`
+}
+
+// Leftmost 最左推导 每一步展开句型中最左边的非终结符 names 为文法的 Names 可以为 nil
+func (n *Node) Leftmost(names map[string]string) *Derivation {
+	return n.derive(LeftmostDerivation, names, func(form []*Node) int {
+		for i, x := range form {
+			if !x.IsLeaf() {
+				return i
+			}
+		}
+		return -1
+	})
+}
+
+// Rightmost 最右推导 每一步展开句型中最右边的非终结符
+func (n *Node) Rightmost(names map[string]string) *Derivation {
+	return n.derive(RightmostDerivation, names, func(form []*Node) int {
+		for i := len(form) - 1; i >= 0; i-- {
+			if !form[i].IsLeaf() {
+				return i
+			}
+		}
+		return -1
+	})
+}
+
+// Reductions 自底向上分析的归约过程 即倒过来的最右推导
+func (n *Node) Reductions(names map[string]string) *Derivation {
+	rm := n.Rightmost(names)
+	d := &Derivation{Kind: Reduction}
+	for i := len(rm.Forms) - 1; i >= 0; i-- {
+		d.Forms = append(d.Forms, rm.Forms[i])
+	}
+	for i := len(rm.Steps) - 1; i >= 0; i-- {
+		d.Steps = append(d.Steps, rm.Steps[i])
+	}
+	return d
+}
+
+// derive 从根结点开始 每次展开 pick 选出的结点 直到句型中只剩叶子
+func (n *Node) derive(kind string, names map[string]string, pick func([]*Node) int) *Derivation {
+	d := &Derivation{Kind: kind}
+	form := []*Node{n}
+	d.Forms = append(d.Forms, display(form, names))
+	for i := pick(form); i >= 0; i = pick(form) {
+		var children []*Node
+		for _, c := range form[i].Children {
+			if c.Symbol != "&" {
+				children = append(children, c)
+			}
+		}
+		d.Steps = append(d.Steps, Rewrite{Left: Name(form[i].Symbol, names), Right: display(children, names), Position: i})
+		form = append(append(append([]*Node(nil), form[:i]...), children...), form[i+1:]...)
+		d.Forms = append(d.Forms, display(form, names))
+	}
+	return d
+}
+
+func display(nodes []*Node, names map[string]string) []string {
+	res := []string{}
+	for _, x := range nodes {
+		res = append(res, Name(x.Symbol, names))
+	}
+	return res
+}
+
+// arrow 文本形式的箭头
+func (d *Derivation) arrow() string {
+	if d.Kind == Reduction {
+		return "<="
+	}
+	return "=>"
+}
+
+// Production 第 i 步使用的产生式 符号之间用空格分隔
+func (d *Derivation) Production(i int) string {
+	right := "ε"
+	if len(d.Steps[i].Right) != 0 {
+		right = strings.Join(d.Steps[i].Right, " ")
+	}
+	return d.Steps[i].Left + " -> " + right
+}
+
+// String 每行一个句型 后面是这一步使用的产生式
+//
+//	E
+//	=> T E'    (E -> T E')
+func (d *Derivation) String() string {
+	var build strings.Builder
+	build.WriteString(formString(d.Forms[0]) + "\n")
+	for i := range d.Steps {
+		build.WriteString(fmt.Sprintf("%s %s    (%s)\n", d.arrow(), formString(d.Forms[i+1]), d.Production(i)))
+	}
+	return build.String()
+}
+
+func formString(form []string) string {
+	if len(form) == 0 {
+		return "ε"
+	}
+	return strings.Join(form, " ")
+}
+
+// LaTeX align* 环境 箭头下标 lm 或 rm
+func (d *Derivation) LaTeX() string {
+	arrow := `\Rightarrow_{lm}`
+	switch d.Kind {
+	case RightmostDerivation:
+		arrow = `\Rightarrow_{rm}`
+	case Reduction:
+		arrow = `\Leftarrow_{rm}`
+	}
+	var build strings.Builder
+	build.WriteString("\\begin{align*}\n")
+	build.WriteString(latexForm(d.Forms[0]))
+	for i := 1; i < len(d.Forms); i++ {
+		build.WriteString(" &" + arrow + " " + latexForm(d.Forms[i]))
+		if i != len(d.Forms)-1 {
+			build.WriteString(` \\` + "\n")
+		}
+	}
+	build.WriteString("\n\\end{align*}\n")
+	return build.String()
+}
+
+// latexSymbols 数学模式中需要转义的符号
+var latexSymbols = map[string]string{
+	"ε": `\varepsilon`, "#": `\#`, "&": `\&`, "%": `\%`, "$": `\$`, "_": `\_`,
+	"{": `\{`, "}": `\}`, "~": `\sim`, "^": `\hat{}`, `\`: `\backslash`, "|": `\mid`,
+}
+
+func latexForm(form []string) string {
+	if len(form) == 0 {
+		return `\varepsilon`
+	}
+	var res []string
+	for _, s := range form {
+		switch {
+		case latexSymbols[s] != "":
+			res = append(res, latexSymbols[s])
+		case len(strings.TrimRight(s, "'")) > 1:
+			// 多个字符的符号用正体 例如 id
+			base := strings.TrimRight(s, "'")
+			res = append(res, `\mathrm{`+strings.ReplaceAll(base, "_", `\_`)+`}`+s[len(base):])
+		default:
+			res = append(res, s)
+		}
+	}
+	return strings.Join(res, `\;`)
+}
+
+// JSON 缩进后的 JSON 形式
+func (d *Derivation) JSON() ([]byte, error) {
+	return json.MarshalIndent(d, "", "  ")
+}
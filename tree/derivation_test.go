@@ -0,0 +1,146 @@
+package tree
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// sum 文法 P->A+B A->id B->num|& 开始符号为 P 且终结符 id 和 num 有多个字符
+func sum() *Node {
+	return New("P",
+		New("A", Leaf("id", nil)),
+		Leaf("+", nil),
+		New("B", Leaf("num", nil)))
+}
+
+func TestLeftmost(t *testing.T) {
+	d := sum().Leftmost(nil)
+	want := `P
+=> A + B    (P -> A + B)
+=> id + B    (A -> id)
+=> id + num    (B -> num)
+`
+	if d.String() != want {
+		t.Fatalf("got\n%s", d.String())
+	}
+	if d.Steps[2].Position != 2 {
+		t.Fatalf("B is the third symbol, got %d", d.Steps[2].Position)
+	}
+}
+
+func TestRightmostAndReductions(t *testing.T) {
+	d := sum().Rightmost(nil)
+	want := `P
+=> A + B    (P -> A + B)
+=> A + num    (B -> num)
+=> id + num    (A -> id)
+`
+	if d.String() != want {
+		t.Fatalf("got\n%s", d.String())
+	}
+	r := sum().Reductions(nil)
+	want = `id + num
+<= A + num    (A -> id)
+<= A + B    (B -> num)
+<= P    (P -> A + B)
+`
+	if r.String() != want {
+		t.Fatalf("got\n%s", r.String())
+	}
+}
+
+func TestEpsilonAndStartSymbol(t *testing.T) {
+	// G 按 names 显示为 E' 空产生式不出现在句型中
+	d := New("G", Leaf("&", nil)).Leftmost(map[string]string{"G": "E'"})
+	if d.String() != "E'\n=> ε    (E' -> ε)\n" {
+		t.Fatalf("got\n%s", d.String())
+	}
+}
+
+func TestLaTeX(t *testing.T) {
+	want := `\begin{align*}
+P &\Rightarrow_{lm} A\;+\;B \\
+ &\Rightarrow_{lm} \mathrm{id}\;+\;B \\
+ &\Rightarrow_{lm} \mathrm{id}\;+\;\mathrm{num}
+\end{align*}
+`
+	if got := sum().Leftmost(nil).LaTeX(); got != want {
+		t.Fatalf("got\n%s", got)
+	}
+	if got := New("E", Leaf("#", nil), Leaf("&", nil)).Reductions(nil).LaTeX(); !strings.Contains(got, `\# &\Leftarrow_{rm} E`) {
+		t.Fatalf("got\n%s", got)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	src, err := sum().Leftmost(nil).JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var d Derivation
+	if err = json.Unmarshal(src, &d); err != nil {
+		t.Fatal(err)
+	}
+	if d.Kind != LeftmostDerivation || len(d.Forms) != 4 || strings.Join(d.Forms[3], " ") != "id + num" || d.Steps[0].Left != "P" {
+		t.Fatalf("unexpected json\n%s", src)
+	}
+}
+
+func TestBuilders(t *testing.T) {
+	steps := []Step{
+		{Derive: true, Left: "E", Right: Symbols("TG")}, {Derive: true, Left: "T", Right: Symbols("i")}, {Right: Symbols("i")},
+		{Derive: true, Left: "G", Right: Symbols("&")}, {Right: Symbols("&")},
+	}
+	root, err := NewBuilder(steps, nil).Build("E")
+	if err != nil || root.String() != "E(T(i) G(ε))" {
+		t.Fatalf("got %v %v", root, err)
+	}
+	if _, err = NewBuilder(steps[1:], nil).Build("E"); err == nil {
+		t.Fatal("T->i does not expand E")
+	}
+	root, err = FromReductions([]Step{{Left: "F", Right: Symbols("i")}, {Left: "T", Right: Symbols("F")}, {Left: "F", Right: Symbols("i")}, {Left: "T", Right: Symbols("T*F")}})
+	if err != nil || root.String() != "T(T(F(i)) * F(i))" {
+		t.Fatalf("got %v %v", root, err)
+	}
+	if _, err = FromReductions([]Step{{Left: "T", Right: Symbols("T*F")}}); err == nil {
+		t.Fatal("T*F needs two nonterminals")
+	}
+}
+
+// TestMultiCharacterSymbols 开始符号为 S 符号有多个字符 并且含有 S 和 G 显示时不能被拆开替换
+func TestMultiCharacterSymbols(t *testing.T) {
+	steps := []Step{
+		{Derive: true, Left: "S", Right: []string{"Stmt", "S"}},
+		{Derive: true, Left: "Stmt", Right: []string{"GoTo", ";"}},
+		{Derive: true, Left: "GoTo", Right: []string{"goto", "id"}}, {Right: []string{"goto"}}, {Right: []string{"id"}},
+		{Right: []string{";"}},
+		{Derive: true, Left: "S", Right: []string{"&"}}, {Right: []string{"&"}},
+	}
+	root, err := NewBuilder(steps, nil).Build("S")
+	if err != nil || root.String() != "S(Stmt(GoTo(goto id) ;) S(ε))" {
+		t.Fatalf("got %v %v", root, err)
+	}
+	want := `S
+=> Stmt S    (S -> Stmt S)
+=> GoTo ; S    (Stmt -> GoTo ;)
+=> goto id ; S    (GoTo -> goto id)
+=> goto id ;    (S -> ε)
+`
+	if got := root.Leftmost(nil).String(); got != want {
+		t.Fatalf("got\n%s", got)
+	}
+	// names 只替换整个符号
+	if got := root.Leftmost(map[string]string{"S": "Program"}).String(); !strings.HasPrefix(got, "Program\n=> Stmt Program    (Program -> Stmt Program)\n") {
+		t.Fatalf("got\n%s", got)
+	}
+	reductions := []Step{
+		{Left: "GoTo", Right: []string{"goto", "id"}},
+		{Left: "Stmt", Right: []string{"GoTo", ";"}},
+		{Left: "S", Right: []string{"&"}},
+		{Left: "S", Right: []string{"Stmt", "S"}},
+	}
+	if root, err = FromReductions(reductions); err != nil || root.String() != "S(Stmt(GoTo(goto id) ;) S(ε))" {
+		t.Fatalf("got %v %v", root, err)
+	}
+}
@@ -0,0 +1,173 @@
+package tree
+
+import (
+	"fmt"
+	"github.com/esonhugh/compiler/grammarLL1/rule"
+	"github.com/esonhugh/compiler/lexer"
+	"github.com/esonhugh/compiler/util/transfer"
+	"strings"
+)
+
+// ForestNode 森林中的符号结点 表示 Symbol 推导出了第 Start 到 End 个词法单元
+// 非终结符结点的每一种推导方式是一个压缩结点
+type ForestNode struct {
+	Symbol string
+	Start  int
+	End    int
+	Token  *lexer.Token
+	Packed []*PackedNode
+}
+
+// PackedNode 压缩结点 一个产生式以及右部每个符号对应的结点
+type PackedNode struct {
+	Formula  *rule.Formula
+	Children []*ForestNode
+}
+
+// Forest 共享压缩语法森林 (SPPF) 相同的 (符号, 起点, 终点) 只有一个结点
+// Earley 和 GLR 分析器都输出这种森林
+type Forest struct {
+	Root  *ForestNode
+	Nodes []*ForestNode // Nodes 按创建顺序的全部结点
+	memo  map[forestKey]*ForestNode
+}
+
+type forestKey struct {
+	symbol     string
+	start, end int
+}
+
+// NewForest 创建一个空的森林
+func NewForest() *Forest {
+	return &Forest{memo: make(map[forestKey]*ForestNode)}
+}
+
+// Node 取得或者创建符号结点 第二个返回值表示是否是新建的
+func (f *Forest) Node(symbol string, start, end int) (*ForestNode, bool) {
+	key := forestKey{symbol, start, end}
+	if n, ok := f.memo[key]; ok {
+		return n, false
+	}
+	n := &ForestNode{Symbol: symbol, Start: start, End: end}
+	f.memo[key] = n
+	f.Nodes = append(f.Nodes, n)
+	return n, true
+}
+
+// Pack 给结点加入一种推导方式 已经存在相同的推导时返回 false
+func (f *Forest) Pack(n *ForestNode, formula *rule.Formula, children []*ForestNode) bool {
+	for _, p := range n.Packed {
+		if p.Formula.Left != formula.Left || p.Formula.Right != formula.Right || len(p.Children) != len(children) {
+			continue
+		}
+		same := true
+		for i := range children {
+			if p.Children[i] != children[i] {
+				same = false
+				break
+			}
+		}
+		if same {
+			return false
+		}
+	}
+	n.Packed = append(n.Packed, &PackedNode{Formula: formula, Children: append([]*ForestNode(nil), children...)})
+	return true
+}
+
+// Trees 从森林中取出至多 limit 棵语法树 limit 小于等于 0 时取出全部
+// 环形推导 (A=>+A) 只展开一次 因此取出的树总是有限的
+func (f *Forest) Trees(limit int) []*Node {
+	if f.Root == nil {
+		return nil
+	}
+	return f.Root.trees(limit, make(map[*ForestNode]bool))
+}
+
+// IsAmbiguous 森林中存在有多种推导方式的结点
+func (f *Forest) IsAmbiguous() bool {
+	for _, n := range f.Nodes {
+		if len(n.Packed) > 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// String 输出森林中的全部非终结符结点以及它们的推导方式
+func (f *Forest) String() string {
+	var build strings.Builder
+	for _, n := range f.Nodes {
+		if n.Token != nil || len(n.Packed) == 0 {
+			continue
+		}
+		var alternatives []string
+		for _, packed := range n.Packed {
+			var children []string
+			for _, c := range packed.Children {
+				children = append(children, c.name())
+			}
+			alternatives = append(alternatives, fmt.Sprintf("%s->%s (%s)",
+				Name(packed.Formula.Left, nil), transfer.TransferWith(packed.Formula.Right, nil), strings.Join(children, " ")))
+		}
+		build.WriteString(fmt.Sprintf("%s: %s\n", n.name(), strings.Join(alternatives, " | ")))
+	}
+	return build.String()
+}
+
+func (n *ForestNode) name() string {
+	return fmt.Sprintf("%s[%d,%d]", Name(n.Symbol, nil), n.Start, n.End)
+}
+
+func (n *ForestNode) trees(limit int, path map[*ForestNode]bool) []*Node {
+	if n.Token != nil {
+		return []*Node{Leaf(n.Symbol, n.Token)}
+	}
+	if path[n] {
+		return nil
+	}
+	path[n] = true
+	defer delete(path, n)
+	var res []*Node
+	for _, packed := range n.Packed {
+		var lists [][]*Node
+		for _, c := range packed.Children {
+			list := c.trees(limit, path)
+			if len(list) == 0 {
+				lists = nil
+				break
+			}
+			lists = append(lists, list)
+		}
+		if len(packed.Children) != 0 && lists == nil {
+			continue
+		}
+		for _, children := range product(lists, limit-len(res)) {
+			res = append(res, Reduce(n.Symbol, children))
+		}
+		if limit > 0 && len(res) >= limit {
+			return res[:limit]
+		}
+	}
+	return res
+}
+
+// product 笛卡尔积 至多 limit 个 limit 小于等于 0 时不限制
+// 结果的前 limit 个只由前 limit 个前缀得到 因此每一步够 limit 个就停止
+func product(lists [][]*Node, limit int) [][]*Node {
+	res := [][]*Node{{}}
+	for _, list := range lists {
+		var next [][]*Node
+	build:
+		for _, prefix := range res {
+			for _, t := range list {
+				if limit > 0 && len(next) >= limit {
+					break build
+				}
+				next = append(next, append(append([]*Node(nil), prefix...), t))
+			}
+		}
+		res = next
+	}
+	return res
+}
@@ -0,0 +1,24 @@
+package tree
+
+import "testing"
+
+func TestProductLimit(t *testing.T) {
+	var list []*Node
+	for i := 0; i < 100; i++ {
+		list = append(list, New("a"))
+	}
+	lists := [][]*Node{list, list, list}
+	all := product(lists[:2], 0)
+	if len(all) != 100*100 {
+		t.Fatalf("got %d", len(all))
+	}
+	res := product(lists, 5)
+	if len(res) != 5 {
+		t.Fatalf("got %d", len(res))
+	}
+	for i, children := range res {
+		if len(children) != 3 || children[0] != list[0] || children[1] != list[0] || children[2] != list[i] {
+			t.Fatalf("product %d is not in order", i)
+		}
+	}
+}
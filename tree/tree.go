@@ -0,0 +1,105 @@
+/*
+Package tree 语法树 各个分析器共用的语法树结点和构造方法
+*/
+package tree
+
+import (
+	"github.com/esonhugh/compiler/lexer"
+	"strings"
+)
+
+// Node 语法树结点 叶子结点的 Token 为匹配到的词法单元
+type Node struct {
+	Symbol   string
+	Token    *lexer.Token
+	Children []*Node
+}
+
+// Leaf 创建一个叶子结点 ε 结点的 Token 为空
+func Leaf(symbol string, token *lexer.Token) *Node {
+	return &Node{Symbol: symbol, Token: token}
+}
+
+// New 创建一个内部结点
+func New(symbol string, children ...*Node) *Node {
+	return &Node{Symbol: symbol, Children: children}
+}
+
+// Reduce 按产生式归约得到的结点 children 为右部对应的结点 空产生式得到 ε 叶子
+func Reduce(symbol string, children []*Node) *Node {
+	if len(children) == 0 {
+		return New(symbol, Leaf("&", nil))
+	}
+	return New(symbol, append([]*Node(nil), children...)...)
+}
+
+// IsLeaf 是否是叶子结点
+func (n *Node) IsLeaf() bool {
+	return len(n.Children) == 0
+}
+
+// Leaves 从左到右的全部叶子 不包括 ε
+func (n *Node) Leaves() []*Node {
+	if n.IsLeaf() {
+		if n.Symbol == "&" {
+			return nil
+		}
+		return []*Node{n}
+	}
+	var res []*Node
+	for _, c := range n.Children {
+		res = append(res, c.Leaves()...)
+	}
+	return res
+}
+
+// Equal 两棵树的结构和符号完全相同
+func (n *Node) Equal(o *Node) bool {
+	if n.Symbol != o.Symbol || len(n.Children) != len(o.Children) {
+		return false
+	}
+	for i := range n.Children {
+		if !n.Children[i].Equal(o.Children[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// String 括号形式 E(T(F(i)) G(ε)) 符号保持原样 只有 & 显示为 ε
+func (n *Node) String() string {
+	return n.Format(nil)
+}
+
+// Format 括号形式 names 中有显示名的符号使用显示名 一般为 rule.Rule.Names
+func (n *Node) Format(names map[string]string) string {
+	var build strings.Builder
+	n.write(&build, names)
+	return build.String()
+}
+
+func (n *Node) write(build *strings.Builder, names map[string]string) {
+	build.WriteString(Name(n.Symbol, names))
+	if n.IsLeaf() {
+		return
+	}
+	build.WriteString("(")
+	for i, c := range n.Children {
+		if i != 0 {
+			build.WriteString(" ")
+		}
+		c.write(build, names)
+	}
+	build.WriteString(")")
+}
+
+// Name 符号的显示形式 整个符号在 names 中查找 不会拆开多个字符的符号 & 显示为 ε
+func Name(symbol string, names map[string]string) string {
+	if name, ok := names[symbol]; ok {
+		return name
+	}
+	if symbol == "&" {
+		return "ε"
+	}
+	return symbol
+}
@@ -13,3 +13,21 @@ func Transfer(str string) string {
 	str = strings.ReplaceAll(str, "&", "ε")
 	return str
 }
+
+// TransferWith 按给定的名字表逐个字符替换 用于文法变换新建的非终结符
+// names 中没有的字符保持原样 & 换为 ε
+func TransferWith(str string, names map[string]string) string {
+	var build strings.Builder
+	for _, c := range str {
+		if name, ok := names[string(c)]; ok {
+			build.WriteString(name)
+			continue
+		}
+		if c == '&' {
+			build.WriteString("ε")
+			continue
+		}
+		build.WriteRune(c)
+	}
+	return build.String()
+}